@@ -381,11 +381,17 @@ func parseDetails(details []interface{}) ErrDetails {
 //
 // This is for internal use only.
 func parseHTTPDetails(gae *googleapi.Error) ErrDetails {
+	return parseDetails(httpDetails(gae))
+}
+
+// httpDetails will convert the given googleapi.Error into the protobuf
+// representation then unmarshal the Any values that contain the error details.
+func httpDetails(gae *googleapi.Error) []interface{} {
 	e := &jsonerror.Error{}
 	if err := protojson.Unmarshal([]byte(gae.Body), e); err != nil {
 		// If the error body does not conform to the error schema, ignore it
 		// altogther. See https://cloud.google.com/apis/design/errors#http_mapping.
-		return ErrDetails{}
+		return nil
 	}
 
 	// Coerce the Any messages into proto.Message then parse the details.
@@ -399,7 +405,82 @@ func parseHTTPDetails(gae *googleapi.Error) ErrDetails {
 		details = append(details, m)
 	}
 
-	return parseDetails(details)
+	return details
+}
+
+// Detail returns the first error detail of type T found in the chain of err,
+// which may include APIErrors, gRPC Status errors and googleapi.Errors. Both
+// the details with a field in ErrDetails and the Unknown ones are searched.
+// For example, to extract a custom error detail:
+//
+//	if d, ok := apierror.Detail[*mypb.CustomError](err); ok {
+//		// ...
+//	}
+func Detail[T proto.Message](err error) (T, bool) {
+	var found T
+	ok := false
+	walkDetails(err, func(d interface{}) bool {
+		found, ok = d.(T)
+		return !ok
+	})
+	return found, ok
+}
+
+// Details returns all error details of type T found in the chain of err, in
+// order. See Detail.
+func Details[T proto.Message](err error) []T {
+	var found []T
+	walkDetails(err, func(d interface{}) bool {
+		if v, ok := d.(T); ok {
+			found = append(found, v)
+		}
+		return true
+	})
+	return found
+}
+
+// walkDetails calls fn with every error detail in the chain of err until fn
+// returns false, and reports whether the walk completed.
+func walkDetails(err error, fn func(interface{}) bool) bool {
+	if err == nil {
+		return true
+	}
+	var details []interface{}
+	follow := true
+	switch e := err.(type) {
+	case *APIError:
+		// The wrapped error is the one the details were parsed from, so the
+		// chain is not followed further.
+		follow = false
+		if e.httpErr != nil {
+			details = httpDetails(e.httpErr)
+		} else {
+			details = e.status.Details()
+		}
+	case interface{ GRPCStatus() *status.Status }:
+		details = e.GRPCStatus().Details()
+	case *googleapi.Error:
+		details = httpDetails(e)
+	}
+	for _, d := range details {
+		if !fn(d) {
+			return false
+		}
+	}
+	if !follow {
+		return true
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkDetails(e.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			if !walkDetails(err, fn) {
+				return false
+			}
+		}
+	}
+	return true
 }
 
 // HTTPCode returns the underlying HTTP response status code. This method returns
@@ -33,6 +33,7 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"os"
 
 	"net/http"
@@ -138,6 +139,110 @@ func TestDetails_ExtractProtoMessage(t *testing.T) {
 		}
 	}
 }
+
+func TestDetail(t *testing.T) {
+	customError := &jsonerror.CustomError{
+		Code:         jsonerror.CustomError_UNIVERSE_WAS_DESTROYED,
+		Entity:       "some entity",
+		ErrorMessage: "custom error message",
+	}
+	otherCustomError := &jsonerror.CustomError{Entity: "other entity"}
+	ei := &errdetails.ErrorInfo{Reason: "just because", Domain: "tests"}
+
+	grpcS, _ := status.New(codes.Unknown, "unknown error").WithDetails(ei, customError, otherCustomError)
+	apiErr, _ := FromError(grpcS.Err())
+
+	var anys []*anypb.Any
+	for _, m := range []proto.Message{ei, customError} {
+		any, err := anypb.New(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		anys = append(anys, any)
+	}
+	data, err := protojson.Marshal(&jsonerror.Error{Error: &jsonerror.Error_Status{Details: anys}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hae := &googleapi.Error{Code: http.StatusInternalServerError, Body: string(data)}
+	httpAPIErr, _ := FromError(hae)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCustom  []*jsonerror.CustomError
+		wantErrInfo *errdetails.ErrorInfo
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "no_details",
+			err:  status.New(codes.Unimplemented, "unimp").Err(),
+		},
+		{
+			name:        "grpc_status",
+			err:         grpcS.Err(),
+			wantCustom:  []*jsonerror.CustomError{customError, otherCustomError},
+			wantErrInfo: ei,
+		},
+		{
+			name:        "api_error",
+			err:         apiErr,
+			wantCustom:  []*jsonerror.CustomError{customError, otherCustomError},
+			wantErrInfo: ei,
+		},
+		{
+			name:        "wrapped_api_error",
+			err:         fmt.Errorf("calling service: %w", apiErr),
+			wantCustom:  []*jsonerror.CustomError{customError, otherCustomError},
+			wantErrInfo: ei,
+		},
+		{
+			name:        "http_error",
+			err:         hae,
+			wantCustom:  []*jsonerror.CustomError{customError},
+			wantErrInfo: ei,
+		},
+		{
+			name:        "http_api_error",
+			err:         httpAPIErr,
+			wantCustom:  []*jsonerror.CustomError{customError},
+			wantErrInfo: ei,
+		},
+		{
+			name:        "joined",
+			err:         errors.Join(errors.New("first"), hae, grpcS.Err()),
+			wantCustom:  []*jsonerror.CustomError{customError, customError, otherCustomError},
+			wantErrInfo: ei,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Details[*jsonerror.CustomError](tc.err)
+			if diff := cmp.Diff(tc.wantCustom, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Details() mismatch (-want +got):\n%s", diff)
+			}
+
+			first, ok := Detail[*jsonerror.CustomError](tc.err)
+			if ok != (len(tc.wantCustom) > 0) {
+				t.Fatalf("Detail() ok = %v, want %v", ok, !ok)
+			}
+			if ok {
+				if diff := cmp.Diff(tc.wantCustom[0], first, protocmp.Transform()); diff != "" {
+					t.Errorf("Detail() mismatch (-want +got):\n%s", diff)
+				}
+			}
+
+			// Details with a field in ErrDetails are found as well.
+			gotInfo, _ := Detail[*errdetails.ErrorInfo](tc.err)
+			if diff := cmp.Diff(tc.wantErrInfo, gotInfo, protocmp.Transform()); diff != "" {
+				t.Errorf("Detail() ErrorInfo mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestUnwrap(t *testing.T) {
 	pf := &errdetails.PreconditionFailure{
 		Violations: []*errdetails.PreconditionFailure_Violation{{Type: "Foo", Subject: "Bar", Description: "desc"}},
@@ -0,0 +1,407 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// Package batcher accumulates individual elements into batches and sends them
+// with a single API call. It is meant for use by the Go Client Libraries for
+// methods that accept many elements at once, such as writing log entries or
+// publishing messages.
+//
+// Elements are grouped by a caller-supplied key, so that only elements
+// destined for the same resource share a batch. A batch is sent once it holds
+// Options.CountThreshold elements or Options.ByteThreshold bytes, or once its
+// oldest element has waited Options.DelayThreshold, whichever comes first.
+// Batches are sent through [gax.Invoke], so they are retried as configured by
+// the call options given to [New]. The results of a batch are split back to
+// the callers of [Batcher.Add].
+//
+// A batch is sent with a context that carries the values of its first
+// element's context and the latest deadline among its elements, and that is
+// canceled once the contexts of all its elements are done, since no caller
+// then wants its results. A timeout set with [gax.WithTimeout] applies to
+// each batch from the time it is sent.
+package batcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+)
+
+// ErrClosed is returned for elements added after the Batcher was closed.
+var ErrClosed = errors.New("batcher: closed")
+
+// BatchCall sends elems, which all share key, in a single API call. It must
+// return one result per element, in the same order. To report that only some
+// elements failed, it returns ElementErrors along with the results.
+type BatchCall[E, R any] func(ctx context.Context, settings gax.CallSettings, key string, elems []E) ([]R, error)
+
+// ElementErrors reports the failure of individual elements of a batch. The
+// error at index i is the error for the element at index i, or nil if the
+// element succeeded.
+type ElementErrors []error
+
+// Error returns the message of the first error.
+func (e ElementErrors) Error() string {
+	n := 0
+	var first error
+	for _, err := range e {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		return "batcher: no errors"
+	case 1:
+		return first.Error()
+	}
+	return fmt.Sprintf("%v (and %d other errors)", first, n-1)
+}
+
+// Options configures when batches are sent. The zero value uses the defaults
+// documented on each field.
+type Options struct {
+	// DelayThreshold is the longest an element waits before its batch is
+	// sent, defaults to 10 milliseconds.
+	DelayThreshold time.Duration
+
+	// CountThreshold is the number of elements that triggers sending a
+	// batch, defaults to 100.
+	CountThreshold int
+
+	// ByteThreshold is the total size of elements, as passed to Add, that
+	// triggers sending a batch, defaults to 1 MB. A batch never exceeds it,
+	// unless a single element does.
+	ByteThreshold int
+
+	// FlowController, if set, bounds the elements that are waiting to be
+	// sent or in flight. Add acquires capacity for each element, and it is
+	// released once the element's result is available.
+	FlowController *gax.FlowController
+}
+
+func (o Options) delayThreshold() time.Duration {
+	if o.DelayThreshold <= 0 {
+		return 10 * time.Millisecond
+	}
+	return o.DelayThreshold
+}
+
+func (o Options) countThreshold() int {
+	if o.CountThreshold <= 0 {
+		return 100
+	}
+	return o.CountThreshold
+}
+
+func (o Options) byteThreshold() int {
+	if o.ByteThreshold <= 0 {
+		return 1e6
+	}
+	return o.ByteThreshold
+}
+
+// Result is the eventual outcome of an element added to a Batcher.
+type Result[R any] struct {
+	done chan struct{}
+	val  R
+	err  error
+}
+
+func newResult[R any]() *Result[R] {
+	return &Result[R]{done: make(chan struct{})}
+}
+
+func (r *Result[R]) set(val R, err error) {
+	r.val, r.err = val, err
+	close(r.done)
+}
+
+// Ready returns a channel that is closed once the result is available.
+func (r *Result[R]) Ready() <-chan struct{} {
+	return r.done
+}
+
+// Get waits for the batch containing the element to be sent and returns the
+// element's result. If ctx is done first, Get returns ctx.Err(), but the
+// element may still be sent.
+func (r *Result[R]) Get(ctx context.Context) (R, error) {
+	select {
+	case <-r.done:
+		return r.val, r.err
+	case <-ctx.Done():
+		var zero R
+		return zero, ctx.Err()
+	}
+}
+
+// item is an element waiting to be sent.
+type item[E, R any] struct {
+	ctx  context.Context
+	elem E
+	size int
+	res  *Result[R]
+}
+
+// batch is a group of items sharing a key.
+type batch[E, R any] struct {
+	key   string
+	items []item[E, R]
+	size  int
+	timer *time.Timer
+	// done is closed once the batch has been sent.
+	done chan struct{}
+}
+
+// Batcher accumulates elements into batches and sends them with a BatchCall.
+// It is safe for concurrent use by multiple goroutines.
+type Batcher[E, R any] struct {
+	call     BatchCall[E, R]
+	opts     Options
+	callOpts []gax.CallOption
+
+	mu      sync.Mutex
+	pending map[string]*batch[E, R]
+	closed  bool
+	// inflight holds the batches being sent.
+	inflight map[*batch[E, R]]struct{}
+}
+
+// New returns a Batcher that sends batches with call, configured by opts.
+// Every batch is sent through gax.Invoke with callOpts.
+func New[E, R any](call BatchCall[E, R], opts Options, callOpts ...gax.CallOption) *Batcher[E, R] {
+	return &Batcher[E, R]{
+		call:     call,
+		opts:     opts,
+		callOpts: append([]gax.CallOption(nil), callOpts...),
+		pending:  make(map[string]*batch[E, R]),
+		inflight: make(map[*batch[E, R]]struct{}),
+	}
+}
+
+// Add adds elem, of the given size in bytes, to the batch for key. If ctx is
+// done before the batch is sent, the element is dropped from it and its result
+// is ctx.Err().
+//
+// If Options.FlowController is set, Add blocks while the limits are reached,
+// as configured by its LimitExceededBehavior.
+func (b *Batcher[E, R]) Add(ctx context.Context, key string, elem E, size int) *Result[R] {
+	var zero R
+	res := newResult[R]()
+	if fc := b.opts.FlowController; fc != nil {
+		if err := fc.Acquire(ctx, size); err != nil {
+			res.set(zero, err)
+			return res
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		b.release(size)
+		res.set(zero, ErrClosed)
+		return res
+	}
+	bt := b.pending[key]
+	if bt != nil && bt.size+size > b.opts.byteThreshold() {
+		b.sendLocked(bt)
+		bt = nil
+	}
+	if bt == nil {
+		bt = &batch[E, R]{key: key}
+		bt.timer = time.AfterFunc(b.opts.delayThreshold(), func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if b.pending[key] == bt {
+				b.sendLocked(bt)
+			}
+		})
+		b.pending[key] = bt
+	}
+	bt.items = append(bt.items, item[E, R]{ctx: ctx, elem: elem, size: size, res: res})
+	bt.size += size
+	if len(bt.items) >= b.opts.countThreshold() || bt.size >= b.opts.byteThreshold() {
+		b.sendLocked(bt)
+	}
+	return res
+}
+
+// Flush sends all pending batches and waits for every batch in flight to
+// complete. Batches started by concurrent calls to Add after Flush was called
+// are not waited for. A batch being retried keeps Flush waiting until its
+// retries end or the contexts of all its elements are done.
+func (b *Batcher[E, R]) Flush() {
+	b.mu.Lock()
+	for _, bt := range b.pending {
+		b.sendLocked(bt)
+	}
+	done := make([]chan struct{}, 0, len(b.inflight))
+	for bt := range b.inflight {
+		done = append(done, bt.done)
+	}
+	b.mu.Unlock()
+	for _, d := range done {
+		<-d
+	}
+}
+
+// Close flushes the Batcher. Elements added after Close fail with ErrClosed.
+func (b *Batcher[E, R]) Close() {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.Flush()
+}
+
+// sendLocked removes bt from the pending batches and sends it in the
+// background. b.mu must be held.
+func (b *Batcher[E, R]) sendLocked(bt *batch[E, R]) {
+	bt.timer.Stop()
+	delete(b.pending, bt.key)
+	bt.done = make(chan struct{})
+	b.inflight[bt] = struct{}{}
+	go func() {
+		b.send(bt)
+		b.mu.Lock()
+		delete(b.inflight, bt)
+		b.mu.Unlock()
+		close(bt.done)
+	}()
+}
+
+// send sends bt and distributes the results to its items.
+func (b *Batcher[E, R]) send(bt *batch[E, R]) {
+	var zero R
+	items := bt.items[:0]
+	for _, it := range bt.items {
+		if err := it.ctx.Err(); err != nil {
+			b.release(it.size)
+			it.res.set(zero, err)
+			continue
+		}
+		items = append(items, it)
+	}
+	if len(items) == 0 {
+		return
+	}
+	elems := make([]E, len(items))
+	for i, it := range items {
+		elems[i] = it.elem
+	}
+
+	ctx, cancel := batchContext(items)
+	defer cancel()
+	var resps []R
+	var elemErrs ElementErrors
+	err := gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {
+		var err error
+		resps, err = b.call(ctx, settings, bt.key, elems)
+		elemErrs = nil
+		if errors.As(err, &elemErrs) {
+			return nil
+		}
+		return err
+	}, b.callOpts...)
+	if err == nil && len(resps) != len(items) {
+		err = fmt.Errorf("batcher: got %d results for %d elements", len(resps), len(items))
+	}
+	if err == nil && elemErrs != nil && len(elemErrs) != len(items) {
+		err = fmt.Errorf("batcher: got %d element errors for %d elements", len(elemErrs), len(items))
+	}
+	for i, it := range items {
+		b.release(it.size)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			// The batch was abandoned because every element's context
+			// is done.
+			it.res.set(zero, it.ctx.Err())
+		case err != nil:
+			it.res.set(zero, err)
+		case elemErrs != nil && elemErrs[i] != nil:
+			it.res.set(zero, elemErrs[i])
+		default:
+			it.res.set(resps[i], nil)
+		}
+	}
+}
+
+// batchContext returns the context to send items with. It carries the
+// values of the first item's context and, if every item's context has a
+// deadline, the latest of them. It is canceled once every item's context is
+// done, or when the returned function is called.
+func batchContext[E, R any](items []item[E, R]) (context.Context, context.CancelFunc) {
+	ctx := context.WithoutCancel(items[0].ctx)
+	var latest time.Time
+	for _, it := range items {
+		d, ok := it.ctx.Deadline()
+		if !ok {
+			latest = time.Time{}
+			break
+		}
+		if d.After(latest) {
+			latest = d
+		}
+	}
+	cancelDeadline := context.CancelFunc(func() {})
+	if !latest.IsZero() {
+		ctx, cancelDeadline = context.WithDeadline(ctx, latest)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	var remaining atomic.Int32
+	remaining.Store(int32(len(items)))
+	stops := make([]func() bool, len(items))
+	for i, it := range items {
+		stops[i] = context.AfterFunc(it.ctx, func() {
+			if remaining.Add(-1) == 0 {
+				cancel(context.Cause(it.ctx))
+			}
+		})
+	}
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel(context.Canceled)
+		cancelDeadline()
+	}
+}
+
+// release returns the flow control capacity held by an element of size.
+func (b *Batcher[E, R]) release(size int) {
+	if fc := b.opts.FlowController; fc != nil {
+		fc.Release(size)
+	}
+}
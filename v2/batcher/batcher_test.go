@@ -0,0 +1,366 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package batcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recorder is a BatchCall that records the batches it receives and echoes
+// each element back, doubled.
+type recorder struct {
+	mu      sync.Mutex
+	batches map[string][][]int
+}
+
+func (r *recorder) call(_ context.Context, _ gax.CallSettings, key string, elems []int) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.batches == nil {
+		r.batches = make(map[string][][]int)
+	}
+	r.batches[key] = append(r.batches[key], append([]int(nil), elems...))
+	resps := make([]int, len(elems))
+	for i, e := range elems {
+		resps[i] = 2 * e
+	}
+	return resps, nil
+}
+
+// sortBatches ignores the order in which concurrently sent batches arrive.
+var sortBatches = cmpopts.SortSlices(func(a, b []int) bool { return a[0] < b[0] })
+
+func TestBatcherCountThreshold(t *testing.T) {
+	var r recorder
+	b := New(r.call, Options{CountThreshold: 2, DelayThreshold: time.Hour})
+	ctx := context.Background()
+	var results []*Result[int]
+	for i := 1; i <= 4; i++ {
+		results = append(results, b.Add(ctx, "k", i, 1))
+	}
+	for i, res := range results {
+		got, err := res.Get(ctx)
+		if err != nil {
+			t.Fatalf("element %d: found error %v", i, err)
+		}
+		if want := 2 * (i + 1); got != want {
+			t.Errorf("element %d: got %d, want %d", i, got, want)
+		}
+	}
+	b.Close()
+	want := map[string][][]int{"k": {{1, 2}, {3, 4}}}
+	if diff := cmp.Diff(want, r.batches, sortBatches); diff != "" {
+		t.Errorf("batches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBatcherByteThreshold(t *testing.T) {
+	var r recorder
+	b := New(r.call, Options{ByteThreshold: 10, DelayThreshold: time.Hour})
+	ctx := context.Background()
+	b.Add(ctx, "k", 1, 4)
+	b.Add(ctx, "k", 2, 4)
+	// Would exceed the threshold, so the first two are sent without it.
+	b.Add(ctx, "k", 3, 4)
+	b.Add(ctx, "k", 4, 6)
+	b.Close()
+	want := map[string][][]int{"k": {{1, 2}, {3, 4}}}
+	if diff := cmp.Diff(want, r.batches, sortBatches); diff != "" {
+		t.Errorf("batches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBatcherDelayThreshold(t *testing.T) {
+	var r recorder
+	b := New(r.call, Options{DelayThreshold: time.Millisecond})
+	ctx := context.Background()
+	got, err := b.Add(ctx, "k", 1, 1).Get(ctx)
+	if err != nil || got != 2 {
+		t.Errorf("got (%d, %v), want (2, nil)", got, err)
+	}
+}
+
+func TestBatcherPartitions(t *testing.T) {
+	var r recorder
+	b := New(r.call, Options{DelayThreshold: time.Hour})
+	ctx := context.Background()
+	b.Add(ctx, "a", 1, 1)
+	b.Add(ctx, "b", 2, 1)
+	b.Add(ctx, "a", 3, 1)
+	b.Flush()
+	want := map[string][][]int{"a": {{1, 3}}, "b": {{2}}}
+	if diff := cmp.Diff(want, r.batches); diff != "" {
+		t.Errorf("batches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBatcherRetry(t *testing.T) {
+	calls := 0
+	call := func(_ context.Context, _ gax.CallSettings, _ string, elems []int) ([]int, error) {
+		calls++
+		if calls == 1 {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+		return elems, nil
+	}
+	retry := gax.WithRetry(func() gax.Retryer {
+		return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Millisecond})
+	})
+	b := New(call, Options{}, retry)
+	ctx := context.Background()
+	res := b.Add(ctx, "k", 7, 1)
+	b.Close()
+	if got, err := res.Get(ctx); err != nil || got != 7 {
+		t.Errorf("got (%d, %v), want (7, nil)", got, err)
+	}
+	if calls != 2 {
+		t.Errorf("BatchCall ran %d times, want 2", calls)
+	}
+}
+
+func TestBatcherErrors(t *testing.T) {
+	errBad := errors.New("bad element")
+	call := func(_ context.Context, _ gax.CallSettings, _ string, elems []int) ([]int, error) {
+		errs := make(ElementErrors, len(elems))
+		for i, e := range elems {
+			if e < 0 {
+				errs[i] = errBad
+			}
+		}
+		return elems, errs
+	}
+	b := New(call, Options{})
+	ctx := context.Background()
+	good := b.Add(ctx, "k", 1, 1)
+	bad := b.Add(ctx, "k", -1, 1)
+	b.Close()
+	if got, err := good.Get(ctx); err != nil || got != 1 {
+		t.Errorf("good element: got (%d, %v), want (1, nil)", got, err)
+	}
+	if _, err := bad.Get(ctx); err != errBad {
+		t.Errorf("bad element: found error %v, want %v", err, errBad)
+	}
+
+	batchErr := errors.New("batch error")
+	b = New(func(context.Context, gax.CallSettings, string, []int) ([]int, error) {
+		return nil, batchErr
+	}, Options{})
+	res := b.Add(ctx, "k", 1, 1)
+	b.Close()
+	if _, err := res.Get(ctx); err != batchErr {
+		t.Errorf("found error %v, want %v", err, batchErr)
+	}
+	if _, err := b.Add(ctx, "k", 1, 1).Get(ctx); err != ErrClosed {
+		t.Errorf("found error %v after Close, want %v", err, ErrClosed)
+	}
+}
+
+func TestBatcherCanceledElement(t *testing.T) {
+	var r recorder
+	b := New(r.call, Options{DelayThreshold: time.Hour})
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	dropped := b.Add(canceled, "k", 1, 1)
+	b.Add(context.Background(), "k", 2, 1)
+	b.Close()
+	if _, err := dropped.Get(context.Background()); err != context.Canceled {
+		t.Errorf("found error %v, want %v", err, context.Canceled)
+	}
+	want := map[string][][]int{"k": {{2}}}
+	if diff := cmp.Diff(want, r.batches); diff != "" {
+		t.Errorf("batches mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestElementErrorsError(t *testing.T) {
+	for _, tst := range []struct {
+		errs ElementErrors
+		want string
+	}{
+		{ElementErrors{nil}, "batcher: no errors"},
+		{ElementErrors{nil, errors.New("a")}, "a"},
+		{ElementErrors{errors.New("a"), errors.New("b"), errors.New("c")}, fmt.Sprintf("a (and %d other errors)", 2)},
+	} {
+		if got := tst.errs.Error(); got != tst.want {
+			t.Errorf("got %q, want %q", got, tst.want)
+		}
+	}
+}
+
+func TestBatcherFlowControl(t *testing.T) {
+	release := make(chan struct{})
+	call := func(_ context.Context, _ gax.CallSettings, _ string, elems []int) ([]int, error) {
+		<-release
+		return elems, nil
+	}
+	fc := gax.NewFlowController(gax.FlowControlSettings{
+		MaxOutstandingElements: 1,
+		LimitExceededBehavior:  gax.FlowControlSignalError,
+	})
+	b := New(call, Options{CountThreshold: 1, FlowController: fc})
+	ctx := context.Background()
+	first := b.Add(ctx, "k", 1, 1)
+	if _, err := b.Add(ctx, "k", 2, 1).Get(ctx); err != gax.ErrFlowControlLimitExceeded {
+		t.Errorf("found error %v, want %v", err, gax.ErrFlowControlLimitExceeded)
+	}
+	close(release)
+	if _, err := first.Get(ctx); err != nil {
+		t.Errorf("found error %v, want nil", err)
+	}
+	b.Close()
+	if got := fc.OutstandingElements(); got != 0 {
+		t.Errorf("got %d outstanding elements after Close, want 0", got)
+	}
+}
+
+func TestBatcherConcurrentFlush(t *testing.T) {
+	var r recorder
+	b := New(r.call, Options{CountThreshold: 3, DelayThreshold: time.Microsecond})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make([][]*Result[int], 4)
+	for g := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				results[g] = append(results[g], b.Add(ctx, fmt.Sprint(g), i, 1))
+			}
+		}()
+	}
+	stop := make(chan struct{})
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				b.Flush()
+			}
+		}
+	}()
+	wg.Wait()
+	close(stop)
+	<-flushed
+	b.Close()
+
+	for g, rs := range results {
+		for i, res := range rs {
+			select {
+			case <-res.Ready():
+			default:
+				t.Fatalf("goroutine %d element %d: result not ready after Close", g, i)
+			}
+			if got, err := res.Get(ctx); err != nil || got != 2*i {
+				t.Errorf("goroutine %d element %d: got %d, %v, want %d, nil", g, i, got, err, 2*i)
+			}
+		}
+	}
+}
+
+func TestBatcherAbandonedBatch(t *testing.T) {
+	// The batch is retried until the contexts of its elements are done.
+	unavailable := func(context.Context, gax.CallSettings, string, []int) ([]int, error) {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	b := New(unavailable, Options{CountThreshold: 2},
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Millisecond, Max: time.Millisecond})
+		}))
+	ctx1, cancel1 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel1()
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	res1 := b.Add(ctx1, "k", 1, 1)
+	res2 := b.Add(ctx2, "k", 2, 1)
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-res1.Ready():
+		t.Fatal("batch abandoned while an element is still wanted")
+	default:
+	}
+	cancel2()
+
+	closed := make(chan struct{})
+	go func() {
+		b.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	if _, err := res1.Get(context.Background()); err != context.DeadlineExceeded {
+		t.Errorf("element 1: found error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := res2.Get(context.Background()); err != context.Canceled {
+		t.Errorf("element 2: found error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestBatcherContext(t *testing.T) {
+	type key struct{}
+	var gotValue any
+	var gotDeadline time.Time
+	call := func(ctx context.Context, _ gax.CallSettings, _ string, elems []int) ([]int, error) {
+		gotValue = ctx.Value(key{})
+		gotDeadline, _ = ctx.Deadline()
+		return elems, nil
+	}
+	b := New(call, Options{CountThreshold: 2})
+	now := time.Now()
+	ctx1, cancel1 := context.WithDeadline(context.WithValue(context.Background(), key{}, "v"), now.Add(time.Minute))
+	defer cancel1()
+	ctx2, cancel2 := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancel2()
+	b.Add(ctx1, "k", 1, 1)
+	b.Add(ctx2, "k", 2, 1)
+	b.Close()
+	if gotValue != "v" {
+		t.Errorf("got value %v, want v", gotValue)
+	}
+	if !gotDeadline.Equal(now.Add(time.Hour)) {
+		t.Errorf("got deadline %v, want %v", gotDeadline, now.Add(time.Hour))
+	}
+}
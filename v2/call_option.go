@@ -30,8 +30,12 @@
 package gax
 
 import (
+	"context"
 	"errors"
 	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"google.golang.org/api/googleapi"
@@ -125,6 +129,10 @@ func (r *boRetryer) Retry(err error) (time.Duration, bool) {
 // the previous attempt returns a googleapi.Error whose status code is stored in
 // cc. Pause times between retries are specified by bo.
 //
+// If the error carries a Retry-After header, in either the delta-seconds or
+// the HTTP-date form, the pause it requests is used instead of the one
+// computed by bo, capped at bo.Max.
+//
 // bo is only used for its parameters; each Retryer has its own copy.
 func OnHTTPCodes(bo Backoff, cc ...int) Retryer {
 	codes := make(map[int]bool, len(cc))
@@ -150,12 +158,35 @@ func (r *httpRetryer) Retry(err error) (time.Duration, bool) {
 	}
 
 	if r.codes[gerr.Code] {
+		if d, ok := retryAfter(gerr.Header, time.Now()); ok {
+			return min(d, r.backoff.maxPause()), true
+		}
 		return r.backoff.Pause(), true
 	}
 
 	return 0, false
 }
 
+// retryAfter parses the Retry-After header in h, relative to now. It returns
+// false if the header is absent or malformed.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
 // Backoff implements backoff logic for retries. The configuration for retries
 // is described in https://google.aip.dev/client-libraries/4221. The current
 // retry limit starts at Initial and increases by a factor of Multiplier every
@@ -166,6 +197,10 @@ func (r *httpRetryer) Retry(err error) (time.Duration, bool) {
 //
 // Note: MaxNumRetries / RPCDeadline is specifically not provided. These should
 // be built on top of Backoff.
+//
+// Backoff is not safe for concurrent use, as Pause updates the current retry
+// period. Use NewSequence to obtain an independent, goroutine-safe sequence
+// of pauses from a shared Backoff.
 type Backoff struct {
 	// Initial is the initial value of the retry period, defaults to 1 second.
 	Initial time.Duration
@@ -181,6 +216,14 @@ type Backoff struct {
 	cur time.Duration
 }
 
+// maxPause returns the configured Max, or its default if unset.
+func (bo *Backoff) maxPause() time.Duration {
+	if bo.Max == 0 {
+		return 30 * time.Second
+	}
+	return bo.Max
+}
+
 // Pause returns the next time.Duration that the caller should use to backoff.
 func (bo *Backoff) Pause() time.Duration {
 	if bo.Initial == 0 {
@@ -207,6 +250,48 @@ func (bo *Backoff) Pause() time.Duration {
 	return d
 }
 
+// Reset restores the current retry period to Initial, so that the next call to
+// Pause behaves as if it were the first one.
+func (bo *Backoff) Reset() {
+	bo.cur = 0
+}
+
+// NewSequence returns a new BackoffSequence that uses the parameters of bo.
+// The sequence starts from Initial, regardless of any prior calls to Pause on
+// bo, and does not modify bo.
+func (bo *Backoff) NewSequence() *BackoffSequence {
+	return &BackoffSequence{
+		bo: Backoff{
+			Initial:    bo.Initial,
+			Max:        bo.Max,
+			Multiplier: bo.Multiplier,
+		},
+	}
+}
+
+// BackoffSequence is a sequence of pause durations computed from the
+// parameters of a Backoff. Unlike Backoff, it is safe for concurrent use by
+// multiple goroutines, which makes it suitable for long-lived background loops
+// that back off on failure and Reset on success.
+type BackoffSequence struct {
+	mu sync.Mutex
+	bo Backoff
+}
+
+// Pause returns the next time.Duration that the caller should use to backoff.
+func (s *BackoffSequence) Pause() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bo.Pause()
+}
+
+// Reset restores the sequence to its initial retry period.
+func (s *BackoffSequence) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bo.Reset()
+}
+
 type grpcOpt []grpc.CallOption
 
 func (o grpcOpt) Resolve(s *CallSettings) {
@@ -250,6 +335,21 @@ func WithTimeout(t time.Duration) CallOption {
 	return &timeoutOpt{t: t}
 }
 
+type sleepOpt struct {
+	sleep func(context.Context, time.Duration) error
+}
+
+func (o sleepOpt) Resolve(s *CallSettings) {
+	s.sleep = o.sleep
+}
+
+// WithSleep replaces the function Invoke uses to pause between retries, which
+// defaults to Sleep. It is meant for testing retry logic with a virtual clock.
+// Deadlines set by WithTimeout or on the context still use real time.
+func WithSleep(sleep func(ctx context.Context, d time.Duration) error) CallOption {
+	return sleepOpt{sleep: sleep}
+}
+
 type clientMetricsOpt struct {
 	cm *ClientMetrics
 }
@@ -285,4 +385,22 @@ type CallSettings struct {
 	// clientMetrics holds the pre-allocated OpenTelemetry metrics instruments
 	// to use for this call.
 	clientMetrics *ClientMetrics
+
+	// retryError reports whether a failed call should return a *RetryError.
+	retryError bool
+
+	// endpoints selects the target endpoint for each attempt.
+	endpoints *EndpointSet
+
+	// cache serves the call from a ResponseCache when possible.
+	cache *responseCacheOpt
+
+	// faults are injected into each attempt, for testing.
+	faults []Fault
+
+	// sleep overrides Sleep, for testing.
+	sleep func(context.Context, time.Duration) error
+
+	// stream reports whether the call establishes a stream for InvokeStream.
+	stream bool
 }
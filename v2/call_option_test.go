@@ -32,6 +32,7 @@ package gax
 import (
 	"context"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -69,6 +70,62 @@ func TestBackoffExponential(t *testing.T) {
 	}
 }
 
+func TestBackoffReset(t *testing.T) {
+	backoff := Backoff{Initial: 1, Max: 20, Multiplier: 2}
+	for i := 0; i < 3; i++ {
+		backoff.Pause()
+	}
+	backoff.Reset()
+	if d := backoff.Pause(); d != 1 {
+		t.Errorf("got %s after Reset, want %s", d, time.Duration(1))
+	}
+}
+
+func TestBackoffSequence(t *testing.T) {
+	backoff := Backoff{Initial: 1, Max: 20, Multiplier: 2}
+	backoff.Pause()
+	backoff.Pause()
+
+	seq := backoff.NewSequence()
+	if seq.bo.cur != 0 {
+		t.Errorf("new sequence has current envelope %s, want 0", seq.bo.cur)
+	}
+	want := []time.Duration{1, 2, 4, 8, 16, 20, 20}
+	for _, w := range want {
+		if d := seq.Pause(); d > w {
+			t.Errorf("Backoff duration should be at most %s, got %s", w, d)
+		}
+	}
+	if backoff.cur != 4 {
+		t.Errorf("NewSequence modified the source Backoff: current envelope is %s, want %s", backoff.cur, time.Duration(4))
+	}
+
+	seq.Reset()
+	if d := seq.Pause(); d != 1 {
+		t.Errorf("got %s after Reset, want %s", d, time.Duration(1))
+	}
+}
+
+func TestBackoffSequenceConcurrent(t *testing.T) {
+	seq := (&Backoff{Initial: time.Millisecond, Max: time.Second}).NewSequence()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if d := seq.Pause(); d > time.Second {
+					t.Errorf("Backoff duration should be at most %s, got %s", time.Second, d)
+				}
+				if j%10 == 0 {
+					seq.Reset()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func TestOnCodes(t *testing.T) {
 	// Lint errors grpc.Errorf in 1.6. It mistakenly expects the first arg to Errorf to be a string.
 	errf := status.Errorf
@@ -130,6 +187,82 @@ func TestOnHTTPCodes(t *testing.T) {
 	}
 }
 
+func TestOnHTTPCodesRetryAfter(t *testing.T) {
+	retryAfter := func(v string) *googleapi.Error {
+		return &googleapi.Error{
+			Code:   http.StatusTooManyRequests,
+			Header: http.Header{"Retry-After": []string{v}},
+		}
+	}
+	tests := []struct {
+		name string
+		err  *googleapi.Error
+		bo   Backoff
+		want time.Duration
+	}{
+		{
+			name: "delta_seconds",
+			err:  retryAfter("7"),
+			want: 7 * time.Second,
+		},
+		{
+			name: "delta_seconds_capped",
+			err:  retryAfter("120"),
+			bo:   Backoff{Max: 10 * time.Second},
+			want: 10 * time.Second,
+		},
+		{
+			name: "delta_seconds_default_cap",
+			err:  retryAfter("120"),
+			want: 30 * time.Second,
+		},
+		{
+			name: "http_date_in_past",
+			err:  retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"),
+			want: 0,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			r := OnHTTPCodes(tst.bo, http.StatusTooManyRequests)
+			d, retry := r.Retry(tst.err)
+			if !retry {
+				t.Fatalf("got no retry, want retry")
+			}
+			if d != tst.want {
+				t.Errorf("got pause %v, want %v", d, tst.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2015, time.October, 21, 7, 28, 0, 0, time.UTC)
+	tests := []struct {
+		v      string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"30", 30 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:05 GMT", 5 * time.Second, true},
+		{"Wed, 21 Oct 2015 07:27:00 GMT", 0, true},
+	}
+	for _, tst := range tests {
+		h := http.Header{}
+		if tst.v != "" {
+			h.Set("Retry-After", tst.v)
+		}
+		got, ok := retryAfter(h, now)
+		if got != tst.want || ok != tst.wantOk {
+			t.Errorf("retryAfter(%q) = (%v, %t), want (%v, %t)", tst.v, got, ok, tst.want, tst.wantOk)
+		}
+	}
+}
+
 func TestWithTimeout(t *testing.T) {
 	settings := CallSettings{}
 	to := 10 * time.Second
@@ -141,6 +274,31 @@ func TestWithTimeout(t *testing.T) {
 	}
 }
 
+func TestWithSleep(t *testing.T) {
+	var pauses []time.Duration
+	sleep := func(_ context.Context, d time.Duration) error {
+		pauses = append(pauses, d)
+		return nil
+	}
+	calls := 0
+	apiCall := func(context.Context, CallSettings) error {
+		calls++
+		if calls < 3 {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	}
+	err := Invoke(context.Background(), apiCall,
+		WithSleep(sleep),
+		WithRetry(func() Retryer { return OnCodes([]codes.Code{codes.Unavailable}, Backoff{Initial: time.Hour}) }))
+	if err != nil {
+		t.Fatalf("found error %v, want nil", err)
+	}
+	if len(pauses) != 2 {
+		t.Errorf("got %d pauses, want 2", len(pauses))
+	}
+}
+
 func TestWithClientMetrics(t *testing.T) {
 	settings := CallSettings{}
 	cm := &ClientMetrics{}
@@ -0,0 +1,174 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// CallGroup coalesces concurrent calls that share a key into a single
+// in-flight call, whose outcome is shared by all of them. It is intended for
+// idempotent reads that many goroutines issue at once, such as fetching
+// configuration or metadata. See InvokeCoalesced.
+//
+// The zero value is ready to use. A CallGroup must not be copied after first
+// use.
+type CallGroup struct {
+	mu    sync.Mutex
+	calls map[string]*groupCall
+}
+
+// groupCall is an in-flight or completed coalesced call.
+type groupCall struct {
+	// done is closed once val and err are set.
+	done chan struct{}
+	val  any
+	err  error
+
+	// waiters is the number of callers still waiting on the call. cancel is
+	// invoked when it drops to zero.
+	waiters int
+	cancel  context.CancelFunc
+
+	// deadline is the latest deadline among the callers that joined the
+	// call. unbounded reports whether any of them had no deadline.
+	deadline  time.Time
+	unbounded bool
+}
+
+// join records a caller waiting on c. g.mu must be held.
+func (c *groupCall) join(ctx context.Context) {
+	c.waiters++
+	if d, ok := ctx.Deadline(); !ok {
+		c.unbounded = true
+	} else if d.After(c.deadline) {
+		c.deadline = d
+	}
+}
+
+// sharedContext is the context a coalesced call runs with. Its deadline is
+// the latest among the callers that have joined the call so far.
+type sharedContext struct {
+	context.Context
+	g *CallGroup
+	c *groupCall
+}
+
+func (s sharedContext) Deadline() (time.Time, bool) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	if s.c.unbounded {
+		return time.Time{}, false
+	}
+	return s.c.deadline, true
+}
+
+// do runs fn, unless a call for key is already in flight, in which case it
+// waits for that call instead. fn runs with a context that carries the values
+// of the first caller's ctx and the latest deadline among the callers, and
+// that is only canceled once every caller waiting on it has given up.
+func (g *CallGroup) do(ctx context.Context, key string, fn func(context.Context) (any, error)) (any, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*groupCall)
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		cctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &groupCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		sctx := sharedContext{Context: cctx, g: g, c: c}
+		go func() {
+			c.val, c.err = fn(sctx)
+			cancel()
+			g.mu.Lock()
+			g.forget(key, c)
+			g.mu.Unlock()
+			close(c.done)
+		}()
+	}
+	c.join(ctx)
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// Nobody is interested in the outcome anymore. Make sure new
+			// callers start a fresh call rather than joining a canceled one.
+			c.cancel()
+			g.forget(key, c)
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// forget removes c from the in-flight calls. g.mu must be held.
+func (g *CallGroup) forget(key string, c *groupCall) {
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
+
+// InvokeCoalesced is like InvokeValue, but coalesces the call with any
+// concurrent calls made with the same g and key. Only one call runs,
+// including any retries, configured by the opts of the caller that started
+// it, and its value and error are returned to every caller.
+//
+// The value produced by the shared call is owned by g. If T is a
+// proto.Message, every caller receives its own clone of it. Otherwise every
+// caller receives a copy of the value, so T should not contain references
+// that callers modify.
+//
+// The shared call runs with a context that carries the values of the first
+// caller's ctx. Its deadline is the latest among the callers' deadlines,
+// and it is canceled only once every caller waiting on it has given up.
+func InvokeCoalesced[T any](ctx context.Context, g *CallGroup, key string, call func(context.Context, CallSettings) (T, error), opts ...CallOption) (T, error) {
+	v, err := g.do(ctx, key, func(ctx context.Context) (any, error) {
+		return InvokeValue(ctx, call, opts...)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if m, ok := v.(proto.Message); ok {
+		v = proto.Clone(m)
+	}
+	resp, _ := v.(T)
+	return resp, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// waitJoined waits until n callers have joined the call for key.
+func waitJoined(g *CallGroup, key string, n int) {
+	for {
+		g.mu.Lock()
+		c := g.calls[key]
+		joined := c != nil && c.waiters == n
+		g.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInvokeCoalesced(t *testing.T) {
+	var g CallGroup
+	var calls atomic.Int32
+	release := make(chan struct{})
+	const n = 5
+
+	var wg sync.WaitGroup
+	resps := make([]*wrapperspb.StringValue, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resps[i], errs[i] = InvokeCoalesced(context.Background(), &g, "key", func(context.Context, CallSettings) (*wrapperspb.StringValue, error) {
+				calls.Add(1)
+				<-release
+				return wrapperspb.String("shared"), nil
+			})
+		}(i)
+	}
+	waitJoined(&g, "key", n)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("APICall ran %d times, want 1", got)
+	}
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Errorf("caller %d: found error %v, want nil", i, errs[i])
+		}
+		if resps[i].GetValue() != "shared" {
+			t.Errorf("caller %d: got response %q, want %q", i, resps[i].GetValue(), "shared")
+		}
+	}
+	// Every caller has its own copy of the response.
+	resps[0].Value = "modified"
+	for i := 1; i < n; i++ {
+		if resps[i].GetValue() != "shared" {
+			t.Errorf("caller %d: got response %q after caller 0 modified its own", i, resps[i].GetValue())
+		}
+	}
+}
+
+func TestInvokeCoalescedError(t *testing.T) {
+	var g CallGroup
+	apiErr := errors.New("foo error")
+	resp, err := InvokeCoalesced(context.Background(), &g, "key", func(context.Context, CallSettings) (string, error) {
+		return "partial", apiErr
+	})
+	if resp != "" || err != apiErr {
+		t.Errorf("got (%q, %v), want (\"\", %v)", resp, err, apiErr)
+	}
+	if len(g.calls) != 0 {
+		t.Errorf("got %d in-flight calls after completion, want 0", len(g.calls))
+	}
+}
+
+func TestInvokeCoalescedDeadline(t *testing.T) {
+	var g CallGroup
+	started := make(chan struct{})
+	release := make(chan struct{})
+	deadlines := make(chan time.Time, 1)
+	call := func(ctx context.Context, _ CallSettings) (int, error) {
+		close(started)
+		<-release
+		d, _ := ctx.Deadline()
+		deadlines <- d
+		return 1, nil
+	}
+
+	now := time.Now()
+	ctx1, cancel1 := context.WithDeadline(context.Background(), now.Add(time.Minute))
+	defer cancel1()
+	ctx2, cancel2 := context.WithDeadline(context.Background(), now.Add(time.Hour))
+	defer cancel2()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		InvokeCoalesced(ctx1, &g, "key", call)
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		InvokeCoalesced(ctx2, &g, "key", call)
+	}()
+	waitJoined(&g, "key", 2)
+	close(release)
+	wg.Wait()
+	if got := <-deadlines; !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("shared call ran with deadline %v, want %v", got, now.Add(time.Hour))
+	}
+}
+
+func TestCallGroupCancel(t *testing.T) {
+	var g CallGroup
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	fn := func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return nil, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	errc := make(chan error, 2)
+	go func() {
+		_, err := g.do(ctx1, "key", fn)
+		errc <- err
+	}()
+	<-started
+	go func() {
+		_, err := g.do(ctx2, "key", func(context.Context) (any, error) {
+			t.Error("second call should have joined the first")
+			return nil, nil
+		})
+		errc <- err
+	}()
+	for {
+		g.mu.Lock()
+		joined := g.calls["key"].waiters == 2
+		g.mu.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel1()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("found error %v, want %v", err, context.Canceled)
+	}
+	select {
+	case <-canceled:
+		t.Fatal("shared call canceled while a caller is still waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel2()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("found error %v, want %v", err, context.Canceled)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("shared call not canceled after all callers gave up")
+	}
+}
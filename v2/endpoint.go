@@ -0,0 +1,199 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EndpointSet tracks the health of an ordered list of equivalent endpoints,
+// such as regional replicas of a service, and selects the one Invoke should
+// target. Endpoints[0] is the primary; the others are used in order when the
+// current endpoint fails FailoverThreshold consecutive times with an
+// Unavailable or connection error, or an HTTP 503 Service Unavailable.
+//
+// Once failed over, the set sticks to the selected endpoint, but probes the
+// primary again every RecoveryInterval. If the probe succeeds, the set fails
+// back to the primary.
+//
+// An EndpointSet must not be copied after first use. It is safe for
+// concurrent use by multiple goroutines, and is intended to be shared by all
+// calls made to the same service.
+type EndpointSet struct {
+	// Endpoints lists the endpoints in order of preference.
+	Endpoints []string
+
+	// FailoverThreshold is the number of consecutive failures against the
+	// current endpoint that triggers a failover, defaults to 3.
+	FailoverThreshold int
+
+	// RecoveryInterval is how long to wait after a failover before probing
+	// the primary endpoint again, defaults to 1 minute.
+	RecoveryInterval time.Duration
+
+	mu sync.Mutex
+	// cur is the index of the selected endpoint.
+	cur int
+	// failures is the number of consecutive failures against cur.
+	failures int
+	// lastProbe is when the primary was last failed away from or probed.
+	lastProbe time.Time
+	// now is time.Now, overridable for testing.
+	now func() time.Time
+}
+
+func (s *EndpointSet) threshold() int {
+	if s.FailoverThreshold <= 0 {
+		return 3
+	}
+	return s.FailoverThreshold
+}
+
+func (s *EndpointSet) recoveryInterval() time.Duration {
+	if s.RecoveryInterval <= 0 {
+		return time.Minute
+	}
+	return s.RecoveryInterval
+}
+
+func (s *EndpointSet) timeNow() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// Current returns the endpoint the next attempt should target. It returns the
+// empty string if Endpoints is empty.
+func (s *EndpointSet) Current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.Endpoints) == 0 {
+		return ""
+	}
+	if s.cur != 0 {
+		if now := s.timeNow(); now.Sub(s.lastProbe) >= s.recoveryInterval() {
+			s.lastProbe = now
+			return s.Endpoints[0]
+		}
+	}
+	return s.Endpoints[s.cur]
+}
+
+// Report records the outcome of an attempt against endpoint. Only
+// Unavailable and connection errors count as failures; any other result means
+// the endpoint was reachable.
+func (s *EndpointSet) Report(endpoint string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.Endpoints) == 0 {
+		return
+	}
+	failed := isEndpointFailure(err)
+	if endpoint != s.Endpoints[s.cur] {
+		// A successful probe of the primary fails back to it. Reports for
+		// any other endpoint are stale and ignored.
+		if endpoint == s.Endpoints[0] && !failed {
+			s.cur = 0
+			s.failures = 0
+		}
+		return
+	}
+	if !failed {
+		s.failures = 0
+		return
+	}
+	s.failures++
+	if s.failures >= s.threshold() {
+		s.cur = (s.cur + 1) % len(s.Endpoints)
+		s.failures = 0
+		s.lastProbe = s.timeNow()
+	}
+}
+
+// isEndpointFailure reports whether err indicates that the endpoint could not
+// be reached.
+func isEndpointFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var operr *net.OpError
+	if errors.As(err, &operr) {
+		return true
+	}
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return gerr.Code == http.StatusServiceUnavailable
+	}
+	if st, ok := status.FromError(err); ok && st.Code() == codes.Unavailable {
+		return true
+	}
+	return false
+}
+
+type endpointSetOpt struct {
+	s *EndpointSet
+}
+
+func (o endpointSetOpt) Resolve(s *CallSettings) {
+	s.endpoints = o.s
+}
+
+// WithEndpointSet configures Invoke to select the target of each attempt from
+// s, and to report the outcome of each attempt back to s. The APICall must
+// read the selected endpoint with EndpointFromContext.
+func WithEndpointSet(s *EndpointSet) CallOption {
+	return endpointSetOpt{s: s}
+}
+
+// endpointKey is the private context key used to store the selected endpoint.
+type endpointKey struct{}
+
+// EndpointFromContext returns the endpoint selected by an EndpointSet for the
+// current attempt. It returns false if the call was not made with
+// WithEndpointSet.
+func EndpointFromContext(ctx context.Context) (string, bool) {
+	ep, ok := ctx.Value(endpointKey{}).(string)
+	return ep, ok
+}
+
+// withEndpoint returns a new context carrying the selected endpoint.
+func withEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, endpoint)
+}
@@ -0,0 +1,166 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestEndpointSetFailover(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	s := &EndpointSet{
+		Endpoints:         []string{"primary", "secondary"},
+		FailoverThreshold: 2,
+		RecoveryInterval:  time.Minute,
+		now:               clock.now,
+	}
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	if got := s.Current(); got != "primary" {
+		t.Fatalf("got %q, want primary", got)
+	}
+	s.Report("primary", unavailable)
+	// A non-connection error means the endpoint is reachable.
+	s.Report("primary", status.Error(codes.NotFound, "not found"))
+	s.Report("primary", unavailable)
+	if got := s.Current(); got != "primary" {
+		t.Fatalf("got %q after non-consecutive failures, want primary", got)
+	}
+	s.Report("primary", &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+	if got := s.Current(); got != "secondary" {
+		t.Fatalf("got %q after consecutive failures, want secondary", got)
+	}
+
+	// Stick to the secondary until the recovery interval elapses.
+	clock.t = clock.t.Add(30 * time.Second)
+	if got := s.Current(); got != "secondary" {
+		t.Fatalf("got %q before recovery interval, want secondary", got)
+	}
+
+	// Failed probe keeps the secondary.
+	clock.t = clock.t.Add(time.Minute)
+	if got := s.Current(); got != "primary" {
+		t.Fatalf("got %q after recovery interval, want primary probe", got)
+	}
+	if got := s.Current(); got != "secondary" {
+		t.Fatalf("got %q while probe in flight, want secondary", got)
+	}
+	s.Report("primary", unavailable)
+	if got := s.Current(); got != "secondary" {
+		t.Fatalf("got %q after failed probe, want secondary", got)
+	}
+
+	// Successful probe fails back to the primary.
+	clock.t = clock.t.Add(time.Minute)
+	if got := s.Current(); got != "primary" {
+		t.Fatalf("got %q after recovery interval, want primary probe", got)
+	}
+	s.Report("primary", nil)
+	if got := s.Current(); got != "primary" {
+		t.Fatalf("got %q after successful probe, want primary", got)
+	}
+}
+
+func TestIsEndpointFailure(t *testing.T) {
+	for _, tst := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"connection", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"grpc_unavailable", status.Error(codes.Unavailable, "unavailable"), true},
+		{"grpc_not_found", status.Error(codes.NotFound, "not found"), false},
+		{"http_503", &googleapi.Error{Code: http.StatusServiceUnavailable}, true},
+		{"http_503_wrapped", fmt.Errorf("wrapped: %w", &googleapi.Error{Code: http.StatusServiceUnavailable}), true},
+		{"http_500", &googleapi.Error{Code: http.StatusInternalServerError}, false},
+	} {
+		if got := isEndpointFailure(tst.err); got != tst.want {
+			t.Errorf("%s: got %t, want %t", tst.name, got, tst.want)
+		}
+	}
+}
+
+func TestEndpointSetEmpty(t *testing.T) {
+	var s EndpointSet
+	if got := s.Current(); got != "" {
+		t.Errorf("got %q, want empty", got)
+	}
+	s.Report("", errors.New("foo error"))
+}
+
+func TestInvokeWithEndpointSet(t *testing.T) {
+	s := &EndpointSet{
+		Endpoints:         []string{"primary", "secondary"},
+		FailoverThreshold: 2,
+	}
+	var got []string
+	apiCall := func(ctx context.Context, _ CallSettings) error {
+		ep, _ := EndpointFromContext(ctx)
+		got = append(got, ep)
+		if ep == "primary" {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	}
+	var settings CallSettings
+	WithRetry(func() Retryer { return boolRetryer(true) }).Resolve(&settings)
+	WithEndpointSet(s).Resolve(&settings)
+	var sp recordSleeper
+	if err := invoke(context.Background(), apiCall, settings, sp.sleep); err != nil {
+		t.Fatalf("found error %v, want nil", err)
+	}
+	if diff := cmp.Diff([]string{"primary", "primary", "secondary"}, got); diff != "" {
+		t.Errorf("endpoints mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestEndpointFromContextUnset(t *testing.T) {
+	if ep, ok := EndpointFromContext(context.Background()); ok {
+		t.Errorf("got %q, want no endpoint", ep)
+	}
+}
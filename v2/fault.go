@@ -0,0 +1,219 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/googleapis/gax-go/v2/callctx"
+	"github.com/googleapis/gax-go/v2/internallog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Fault describes a failure that Invoke injects into calls, to exercise retry
+// and timeout configurations without a cooperating backend. Faults are
+// applied to each attempt before the APICall runs. See WithFaults.
+type Fault struct {
+	// Method restricts the fault to calls whose rpc_method telemetry context
+	// value, as set by generated clients, equals Method. Empty matches every
+	// call.
+	Method string
+
+	// Probability is the chance, between 0 and 1, that an attempt is
+	// affected, defaults to 1.
+	Probability float64
+
+	// Delay is added before the attempt.
+	Delay time.Duration
+
+	// Drop simulates a request that is lost: the APICall does not run and the
+	// attempt only returns once its context is done.
+	Drop bool
+
+	// Status, if set, is returned as the attempt's error instead of running
+	// the APICall. Details attached to it are surfaced by apierror. A status
+	// with code OK is ignored.
+	Status *status.Status
+}
+
+func (f Fault) probability() float64 {
+	if f.Probability <= 0 {
+		return 1
+	}
+	return f.Probability
+}
+
+type faultsOpt []Fault
+
+func (o faultsOpt) Resolve(s *CallSettings) {
+	s.faults = o
+}
+
+// WithFaults configures Invoke to inject faults into the call. It is meant for
+// testing only.
+//
+// Faults can also be configured for every call, without changing code, by
+// setting the GOOGLE_SDK_GO_FAULT_INJECTION environment variable to a JSON
+// array of objects with the fields "method", "probability", "delay" (a
+// time.ParseDuration string), "drop", "code" (e.g. "UNAVAILABLE"), "message",
+// "reason" and "domain". If "reason" is set, the status carries an ErrorInfo
+// detail with the given reason and domain. "probability" defaults to 1, and
+// must otherwise be greater than 0 and at most 1. Faults from the environment
+// apply in addition to those given to WithFaults. An invalid configuration,
+// including the code "OK", injects no faults and is reported through the
+// logger enabled by GOOGLE_SDK_GO_LOGGING_LEVEL.
+func WithFaults(faults ...Fault) CallOption {
+	return faultsOpt(append([]Fault(nil), faults...))
+}
+
+// faultConfig is the JSON form of a Fault.
+type faultConfig struct {
+	Method      string   `json:"method"`
+	Probability *float64 `json:"probability"`
+	Delay       string   `json:"delay"`
+	Drop        bool     `json:"drop"`
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	Reason      string   `json:"reason"`
+	Domain      string   `json:"domain"`
+}
+
+// envFaults returns the faults configured by GOOGLE_SDK_GO_FAULT_INJECTION.
+var envFaults = sync.OnceValue(func() []Fault {
+	return loadFaults(os.Getenv("GOOGLE_SDK_GO_FAULT_INJECTION"), internallog.New(nil))
+})
+
+// loadFaults parses the JSON configuration described in WithFaults. An invalid
+// configuration injects no faults, and the reason is logged to logger.
+func loadFaults(s string, logger *slog.Logger) []Fault {
+	faults, err := parseFaults(s)
+	if err != nil {
+		logger.Warn("gax: ignoring invalid GOOGLE_SDK_GO_FAULT_INJECTION", "error", err)
+		return nil
+	}
+	return faults
+}
+
+// parseFaults parses the JSON configuration described in WithFaults.
+func parseFaults(s string) ([]Fault, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var configs []faultConfig
+	if err := json.Unmarshal([]byte(s), &configs); err != nil {
+		return nil, err
+	}
+	faults := make([]Fault, 0, len(configs))
+	for i, c := range configs {
+		f := Fault{Method: c.Method, Drop: c.Drop}
+		if p := c.Probability; p != nil {
+			if *p <= 0 || *p > 1 {
+				return nil, fmt.Errorf("fault %d: probability %v is not in (0, 1]", i, *p)
+			}
+			f.Probability = *p
+		}
+		if c.Delay != "" {
+			d, err := time.ParseDuration(c.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("fault %d: %w", i, err)
+			}
+			f.Delay = d
+		}
+		if c.Code != "" {
+			code, ok := parseCode(c.Code)
+			if !ok {
+				return nil, fmt.Errorf("fault %d: unknown code %q", i, c.Code)
+			}
+			if code == codes.OK {
+				return nil, fmt.Errorf("fault %d: code %q is not an error", i, c.Code)
+			}
+			f.Status = status.New(code, c.Message)
+			if c.Reason != "" {
+				if st, err := f.Status.WithDetails(&errdetails.ErrorInfo{Reason: c.Reason, Domain: c.Domain}); err == nil {
+					f.Status = st
+				}
+			}
+		}
+		faults = append(faults, f)
+	}
+	return faults, nil
+}
+
+// parseCode parses a status code from its canonical name or number.
+func parseCode(s string) (codes.Code, bool) {
+	for i, name := range codeToStr {
+		if name == s {
+			return codes.Code(i), true
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(codeToStr) {
+		return codes.Code(n), true
+	}
+	return 0, false
+}
+
+// withFaults wraps call so that faults are injected into each attempt.
+func withFaults(call APICall, faults []Fault) APICall {
+	return func(ctx context.Context, settings CallSettings) error {
+		method, _ := callctx.TelemetryFromContext(ctx, "rpc_method")
+		for _, f := range faults {
+			if f.Method != "" && f.Method != method {
+				continue
+			}
+			if p := f.probability(); p < 1 && rand.Float64() >= p {
+				continue
+			}
+			if f.Delay > 0 {
+				if err := Sleep(ctx, f.Delay); err != nil {
+					return err
+				}
+			}
+			if f.Drop {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			// An OK status is not an error, so the call proceeds.
+			if f.Status != nil && f.Status.Code() != codes.OK {
+				return f.Status.Err()
+			}
+		}
+		return call(ctx, settings)
+	}
+}
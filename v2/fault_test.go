@@ -0,0 +1,221 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/googleapis/gax-go/v2/apierror"
+	"github.com/googleapis/gax-go/v2/callctx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInvokeWithFaults(t *testing.T) {
+	unavailable := status.New(codes.Unavailable, "injected")
+	for _, tst := range []struct {
+		name      string
+		method    string
+		faults    []Fault
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{
+			name:      "status",
+			faults:    []Fault{{Probability: 1, Status: unavailable}},
+			wantCode:  codes.Unavailable,
+			wantCalls: 0,
+		},
+		{
+			name:      "default_probability",
+			faults:    []Fault{{Status: unavailable}},
+			wantCode:  codes.Unavailable,
+			wantCalls: 0,
+		},
+		{
+			name:      "matching_method",
+			method:    "Get",
+			faults:    []Fault{{Method: "Get", Probability: 1, Status: unavailable}},
+			wantCode:  codes.Unavailable,
+			wantCalls: 0,
+		},
+		{
+			name:      "other_method",
+			method:    "List",
+			faults:    []Fault{{Method: "Get", Probability: 1, Status: unavailable}},
+			wantCode:  codes.OK,
+			wantCalls: 1,
+		},
+		{
+			name:      "delay_only",
+			faults:    []Fault{{Probability: 1, Delay: time.Millisecond}},
+			wantCode:  codes.OK,
+			wantCalls: 1,
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tst.method != "" {
+				ctx = callctx.WithTelemetryContext(ctx, "rpc_method", tst.method)
+			}
+			calls := 0
+			apiCall := func(context.Context, CallSettings) error {
+				calls++
+				return nil
+			}
+			var settings CallSettings
+			WithFaults(tst.faults...).Resolve(&settings)
+			var sp recordSleeper
+			err := invoke(ctx, apiCall, settings, sp.sleep)
+			if got := status.Code(err); got != tst.wantCode {
+				t.Errorf("got code %v, want %v", got, tst.wantCode)
+			}
+			if calls != tst.wantCalls {
+				t.Errorf("APICall ran %d times, want %d", calls, tst.wantCalls)
+			}
+		})
+	}
+}
+
+func TestInvokeWithFaultsRetry(t *testing.T) {
+	// A fault is injected into every attempt, so the retryer sees it each time.
+	calls := 0
+	apiCall := func(context.Context, CallSettings) error {
+		calls++
+		return nil
+	}
+	var settings CallSettings
+	WithFaults(Fault{Probability: 1, Status: status.New(codes.Unavailable, "injected")}).Resolve(&settings)
+	WithRetry(func() Retryer { return &constRetryer{max: 2} }).Resolve(&settings)
+	var sp recordSleeper
+	err := invoke(context.Background(), apiCall, settings, sp.sleep)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("found error %v, want %v", err, codes.Unavailable)
+	}
+	if sp != 2 {
+		t.Errorf("slept %d times, want 2", int(sp))
+	}
+	if calls != 0 {
+		t.Errorf("APICall ran %d times, want 0", calls)
+	}
+}
+
+func TestInvokeWithFaultsDrop(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var settings CallSettings
+	WithFaults(Fault{Probability: 1, Drop: true}).Resolve(&settings)
+	var sp recordSleeper
+	err := invoke(ctx, func(context.Context, CallSettings) error { return nil }, settings, sp.sleep)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("found error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestParseFaults(t *testing.T) {
+	faults, err := parseFaults(`[{"method":"Get","probability":0.5,"delay":"100ms","code":"RESOURCE_EXHAUSTED","message":"quota","reason":"RATE_LIMIT_EXCEEDED","domain":"googleapis.com"},{"drop":true,"probability":1}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faults) != 2 {
+		t.Fatalf("got %d faults, want 2", len(faults))
+	}
+	f := faults[0]
+	if f.Method != "Get" || f.Probability != 0.5 || f.Delay != 100*time.Millisecond || f.Drop {
+		t.Errorf("got fault %+v", f)
+	}
+	apierr, ok := apierror.FromError(f.Status.Err())
+	if !ok {
+		t.Fatalf("injected status is not an APIError")
+	}
+	if st := apierr.GRPCStatus(); st.Code() != codes.ResourceExhausted || st.Message() != "quota" {
+		t.Errorf("got status %v", st)
+	}
+	if apierr.Reason() != "RATE_LIMIT_EXCEEDED" || apierr.Domain() != "googleapis.com" {
+		t.Errorf("got reason %q and domain %q", apierr.Reason(), apierr.Domain())
+	}
+	if !faults[1].Drop {
+		t.Errorf("got fault %+v, want Drop", faults[1])
+	}
+	if got := faults[1].probability(); got != 1 {
+		t.Errorf("got probability %v, want 1", got)
+	}
+	if faults, err := parseFaults(`[{"drop":true}]`); err != nil || faults[0].probability() != 1 {
+		t.Errorf("parseFaults() without probability = %+v, %v, want probability 1", faults, err)
+	}
+
+	for _, invalid := range []string{`not json`, `[{"code":"NOPE"}]`, `[{"delay":"soon"}]`, `[{"code":"OK"}]`, `[{"code":"0"}]`, `[{"probability":0}]`, `[{"probability":1.5}]`} {
+		if got, err := parseFaults(invalid); got != nil || err == nil {
+			t.Errorf("parseFaults(%q) = %v, %v, want nil and an error", invalid, got, err)
+		}
+	}
+	if code, ok := parseCode("14"); !ok || code != codes.Unavailable {
+		t.Errorf("parseCode(%q) = %v, %t", "14", code, ok)
+	}
+}
+
+func TestLoadFaultsLogsInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	if got := loadFaults(`[{"code":"NOPE"}]`, logger); got != nil {
+		t.Errorf("loadFaults() = %v, want nil", got)
+	}
+	if !strings.Contains(buf.String(), "GOOGLE_SDK_GO_FAULT_INJECTION") || !strings.Contains(buf.String(), "NOPE") {
+		t.Errorf("got log %q, want a warning about the invalid configuration", buf.String())
+	}
+
+	buf.Reset()
+	if got := loadFaults(`[{"drop":true}]`, logger); len(got) != 1 {
+		t.Errorf("loadFaults() = %v, want 1 fault", got)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("got log %q for a valid configuration, want none", buf.String())
+	}
+}
+
+func TestInvokeWithOKFault(t *testing.T) {
+	calls := 0
+	var settings CallSettings
+	WithFaults(Fault{Probability: 1, Status: status.New(codes.OK, "")}).Resolve(&settings)
+	var sp recordSleeper
+	err := invoke(context.Background(), func(context.Context, CallSettings) error {
+		calls++
+		return nil
+	}, settings, sp.sleep)
+	if err != nil || calls != 1 {
+		t.Errorf("got error %v after %d calls, want nil after 1 call", err, calls)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// LimitExceededBehavior configures what a FlowController does when acquiring
+// would exceed its limits.
+type LimitExceededBehavior int
+
+const (
+	// FlowControlBlock waits until enough capacity is released.
+	FlowControlBlock LimitExceededBehavior = iota
+	// FlowControlIgnore disables the limits. Outstanding elements and bytes
+	// are still tracked.
+	FlowControlIgnore
+	// FlowControlSignalError fails with ErrFlowControlLimitExceeded.
+	FlowControlSignalError
+)
+
+// ErrFlowControlLimitExceeded is returned by FlowController.Acquire when the
+// limits would be exceeded and the behavior is FlowControlSignalError, or
+// when a single request exceeds MaxOutstandingBytes.
+var ErrFlowControlLimitExceeded = errors.New("gax: flow control limits exceeded")
+
+// FlowControlSettings configures a FlowController.
+type FlowControlSettings struct {
+	// MaxOutstandingElements is the maximum number of elements held at once.
+	// Zero means no limit.
+	MaxOutstandingElements int
+
+	// MaxOutstandingBytes is the maximum total size of elements held at once.
+	// Zero means no limit.
+	MaxOutstandingBytes int
+
+	// LimitExceededBehavior is what Acquire does when the limits would be
+	// exceeded, defaults to FlowControlBlock.
+	LimitExceededBehavior LimitExceededBehavior
+
+	// ClientMetrics, if set, receives the number of outstanding elements and
+	// bytes.
+	ClientMetrics *ClientMetrics
+}
+
+// FlowController bounds the number and total size of elements that are
+// outstanding at once, such as messages buffered by a streaming or batching
+// client. Blocked callers are admitted in the order they called Acquire.
+// It is safe for concurrent use by multiple goroutines.
+type FlowController struct {
+	settings FlowControlSettings
+
+	mu       sync.Mutex
+	elements int
+	bytes    int
+	// waiters holds the *flowWaiter blocked in Acquire, in FIFO order.
+	waiters list.List
+}
+
+// flowWaiter is a caller blocked in Acquire.
+type flowWaiter struct {
+	size int
+	// ready is closed once the capacity has been granted.
+	ready chan struct{}
+}
+
+// NewFlowController returns a FlowController configured by s.
+func NewFlowController(s FlowControlSettings) *FlowController {
+	return &FlowController{settings: s}
+}
+
+// Acquire reserves capacity for one element of the given size in bytes. Each
+// successful Acquire must be followed by a Release of the same size. If ctx is
+// done while waiting, Acquire returns ctx.Err() without reserving capacity.
+func (fc *FlowController) Acquire(ctx context.Context, size int) error {
+	behavior := fc.settings.LimitExceededBehavior
+	if behavior != FlowControlIgnore && fc.settings.MaxOutstandingBytes > 0 && size > fc.settings.MaxOutstandingBytes {
+		return fmt.Errorf("%w: element of %d bytes exceeds limit of %d bytes", ErrFlowControlLimitExceeded, size, fc.settings.MaxOutstandingBytes)
+	}
+
+	fc.mu.Lock()
+	if behavior == FlowControlIgnore || (fc.waiters.Len() == 0 && fc.fitsLocked(size)) {
+		fc.addLocked(1, size)
+		fc.mu.Unlock()
+		return nil
+	}
+	if behavior == FlowControlSignalError {
+		fc.mu.Unlock()
+		return ErrFlowControlLimitExceeded
+	}
+	w := &flowWaiter{size: size, ready: make(chan struct{})}
+	el := fc.waiters.PushBack(w)
+	fc.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		fc.mu.Lock()
+		select {
+		case <-w.ready:
+			// Granted concurrently with the cancellation; give it back.
+			fc.mu.Unlock()
+			fc.Release(size)
+			return ctx.Err()
+		default:
+		}
+		head := fc.waiters.Front() == el
+		fc.waiters.Remove(el)
+		if head {
+			// The waiters behind this one may fit now.
+			fc.grantLocked()
+		}
+		fc.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Release returns the capacity reserved by a successful Acquire of the given
+// size.
+func (fc *FlowController) Release(size int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.addLocked(-1, -size)
+	fc.grantLocked()
+}
+
+// OutstandingElements returns the number of elements currently held.
+func (fc *FlowController) OutstandingElements() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.elements
+}
+
+// OutstandingBytes returns the total size of elements currently held.
+func (fc *FlowController) OutstandingBytes() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.bytes
+}
+
+// fitsLocked reports whether an element of size fits within the limits.
+// fc.mu must be held.
+func (fc *FlowController) fitsLocked(size int) bool {
+	if max := fc.settings.MaxOutstandingElements; max > 0 && fc.elements+1 > max {
+		return false
+	}
+	if max := fc.settings.MaxOutstandingBytes; max > 0 && fc.bytes+size > max {
+		return false
+	}
+	return true
+}
+
+// grantLocked admits waiters, in order, for as long as they fit. fc.mu must be
+// held.
+func (fc *FlowController) grantLocked() {
+	for el := fc.waiters.Front(); el != nil; el = fc.waiters.Front() {
+		w := el.Value.(*flowWaiter)
+		if !fc.fitsLocked(w.size) {
+			return
+		}
+		fc.waiters.Remove(el)
+		fc.addLocked(1, w.size)
+		close(w.ready)
+	}
+}
+
+// addLocked adjusts the outstanding elements and bytes and records the change.
+// fc.mu must be held.
+func (fc *FlowController) addLocked(elements, bytes int) {
+	fc.elements += elements
+	fc.bytes += bytes
+	cm := fc.settings.ClientMetrics
+	if cm == nil || !IsFeatureEnabled("METRICS") {
+		return
+	}
+	ec, bc := cm.flowControlCounters()
+	opt := metric.WithAttributes(cm.attributes()...)
+	if ec != nil {
+		ec.Add(context.Background(), int64(elements), opt)
+	}
+	if bc != nil {
+		bc.Add(context.Background(), int64(bytes), opt)
+	}
+}
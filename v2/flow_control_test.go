@@ -0,0 +1,200 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestFlowControllerBlock(t *testing.T) {
+	fc := NewFlowController(FlowControlSettings{MaxOutstandingElements: 2, MaxOutstandingBytes: 10})
+	ctx := context.Background()
+	if err := fc.Acquire(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := fc.Acquire(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if err := fc.Acquire(ctx, 4); err != nil {
+			t.Error(err)
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Acquire did not block at the element limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	fc.Release(4)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Acquire did not unblock after Release")
+	}
+	if got, want := fc.OutstandingElements(), 2; got != want {
+		t.Errorf("got %d outstanding elements, want %d", got, want)
+	}
+	if got, want := fc.OutstandingBytes(), 8; got != want {
+		t.Errorf("got %d outstanding bytes, want %d", got, want)
+	}
+}
+
+func TestFlowControllerFIFO(t *testing.T) {
+	fc := NewFlowController(FlowControlSettings{MaxOutstandingBytes: 10})
+	ctx := context.Background()
+	if err := fc.Acquire(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// A large request at the head of the queue must not be overtaken by a
+	// smaller one that would fit sooner.
+	order := make(chan int, 2)
+	go func() {
+		fc.Acquire(ctx, 8)
+		order <- 8
+	}()
+	for fc.waitersLen() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		fc.Acquire(ctx, 2)
+		order <- 2
+	}()
+	for fc.waitersLen() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fc.Release(5)
+	select {
+	case n := <-order:
+		t.Fatalf("request of %d bytes admitted out of order", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+	fc.Release(5)
+	<-order
+	<-order
+	if got, want := fc.OutstandingBytes(), 10; got != want {
+		t.Errorf("got %d outstanding bytes, want %d", got, want)
+	}
+}
+
+func (fc *FlowController) waitersLen() int {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.waiters.Len()
+}
+
+func TestFlowControllerCancel(t *testing.T) {
+	fc := NewFlowController(FlowControlSettings{MaxOutstandingElements: 1})
+	if err := fc.Acquire(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := fc.Acquire(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("found error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := fc.waitersLen(); got != 0 {
+		t.Errorf("got %d waiters after cancellation, want 0", got)
+	}
+	fc.Release(1)
+	if got := fc.OutstandingElements(); got != 0 {
+		t.Errorf("got %d outstanding elements, want 0", got)
+	}
+}
+
+func TestFlowControllerBehaviors(t *testing.T) {
+	ctx := context.Background()
+
+	fc := NewFlowController(FlowControlSettings{MaxOutstandingElements: 1, LimitExceededBehavior: FlowControlSignalError})
+	if err := fc.Acquire(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := fc.Acquire(ctx, 1); err != ErrFlowControlLimitExceeded {
+		t.Errorf("found error %v, want %v", err, ErrFlowControlLimitExceeded)
+	}
+
+	fc = NewFlowController(FlowControlSettings{MaxOutstandingElements: 1, LimitExceededBehavior: FlowControlIgnore})
+	for i := 0; i < 3; i++ {
+		if err := fc.Acquire(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fc.OutstandingElements(); got != 3 {
+		t.Errorf("got %d outstanding elements, want 3", got)
+	}
+
+	fc = NewFlowController(FlowControlSettings{MaxOutstandingBytes: 10})
+	if err := fc.Acquire(ctx, 11); !errors.Is(err, ErrFlowControlLimitExceeded) {
+		t.Errorf("found error %v for oversized element, want %v", err, ErrFlowControlLimitExceeded)
+	}
+}
+
+func TestFlowControllerMetrics(t *testing.T) {
+	t.Setenv("GOOGLE_SDK_GO_EXPERIMENTAL_METRICS", "true")
+	TestOnlyResetIsFeatureEnabled()
+	defer TestOnlyResetIsFeatureEnabled()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	fc := NewFlowController(FlowControlSettings{ClientMetrics: NewClientMetrics(WithMeterProvider(provider))})
+	ctx := context.Background()
+	fc.Acquire(ctx, 3)
+	fc.Acquire(ctx, 4)
+	fc.Release(3)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				got[m.Name] += dp.Value
+			}
+		}
+	}
+	want := map[string]int64{flowElementsMetricName: 1, flowBytesMetricName: 4}
+	for name, v := range want {
+		if got[name] != v {
+			t.Errorf("%s: got %d, want %d", name, got[name], v)
+		}
+	}
+}
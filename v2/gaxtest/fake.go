@@ -0,0 +1,226 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gaxtest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Step is one scripted outcome of a FakeCall.
+type Step[T any] struct {
+	// Resp is stored as the response when Err is nil.
+	Resp T
+	// Err is returned by the attempt.
+	Err error
+}
+
+// Fail returns a Step that fails with err.
+func Fail[T any](err error) Step[T] {
+	return Step[T]{Err: err}
+}
+
+// Succeed returns a Step that succeeds with resp.
+func Succeed[T any](resp T) Step[T] {
+	return Step[T]{Resp: resp}
+}
+
+// FakeCall is a scripted APICall. Each attempt consumes the next Step; once
+// the script is exhausted, the last Step is repeated. It is safe for
+// concurrent use by multiple goroutines.
+type FakeCall[T any] struct {
+	mu       sync.Mutex
+	steps    []Step[T]
+	attempts int
+}
+
+// NewFakeCall returns a FakeCall that plays steps in order. With no steps,
+// every attempt succeeds with the zero value of T.
+func NewFakeCall[T any](steps ...Step[T]) *FakeCall[T] {
+	return &FakeCall[T]{steps: append([]Step[T](nil), steps...)}
+}
+
+// Bind returns an APICall that plays the script, storing successful
+// responses in resp. resp may be nil.
+func (f *FakeCall[T]) Bind(resp *T) gax.APICall {
+	return func(ctx context.Context, _ gax.CallSettings) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		f.mu.Lock()
+		var step Step[T]
+		if len(f.steps) > 0 {
+			step = f.steps[min(f.attempts, len(f.steps)-1)]
+		}
+		f.attempts++
+		f.mu.Unlock()
+		if step.Err == nil && resp != nil {
+			*resp = step.Resp
+		}
+		return step.Err
+	}
+}
+
+// Attempts returns the number of attempts made so far.
+func (f *FakeCall[T]) Attempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts
+}
+
+// StatusError returns a gRPC status error with the given code, message and
+// details, such as those in package errdetails. It panics if the details
+// cannot be marshaled.
+func StatusError(c codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	st := status.New(c, msg)
+	if len(details) > 0 {
+		var err error
+		if st, err = st.WithDetails(details...); err != nil {
+			panic("gaxtest: " + err.Error())
+		}
+	}
+	return st.Err()
+}
+
+// HTTPError returns a googleapi.Error with the given HTTP status code and
+// message, as returned by REST transports. Header holds the optional
+// response headers, such as Retry-After.
+func HTTPError(code int, msg string, header http.Header) *googleapi.Error {
+	return &googleapi.Error{Code: code, Message: msg, Header: header}
+}
+
+// Clock is a virtual clock for testing retry logic without waiting. Pass
+// Clock.CallOption to gax.Invoke so that pauses between retries advance the
+// clock instead of sleeping. It is safe for concurrent use by multiple
+// goroutines.
+//
+// Context deadlines, including those set by gax.WithTimeout, still use real
+// time.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	pauses []time.Duration
+}
+
+// NewClock returns a Clock set to start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the current virtual time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleep records d as a pause and advances the clock by d, without waiting. It
+// has the same signature as gax.Sleep, and returns ctx.Err() if ctx is done.
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pauses = append(c.pauses, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+// CallOption returns a gax.CallOption that makes gax.Invoke pause with Sleep.
+func (c *Clock) CallOption() gax.CallOption {
+	return gax.WithSleep(c.Sleep)
+}
+
+// Pauses returns the pauses recorded by Sleep, in order.
+func (c *Clock) Pauses() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration(nil), c.pauses...)
+}
+
+// AssertAttempts reports a test error if f did not make exactly want
+// attempts.
+func AssertAttempts[T any](t testing.TB, f *FakeCall[T], want int) {
+	t.Helper()
+	if got := f.Attempts(); got != want {
+		t.Errorf("got %d attempts, want %d", got, want)
+	}
+}
+
+// AssertPauses reports a test error if c did not record exactly the pauses in
+// want.
+func AssertPauses(t testing.TB, c *Clock, want ...time.Duration) {
+	t.Helper()
+	got := c.Pauses()
+	if len(got) != len(want) {
+		t.Errorf("got pauses %v, want %v", got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got pauses %v, want %v", got, want)
+			return
+		}
+	}
+}
+
+// AssertPausesAtMost reports a test error if c did not record one pause per
+// bound in max, each no longer than its bound. It suits the jittered pauses
+// of gax.Backoff, whose bounds are known but whose values are random.
+func AssertPausesAtMost(t testing.TB, c *Clock, max ...time.Duration) {
+	t.Helper()
+	got := c.Pauses()
+	if len(got) != len(max) {
+		t.Errorf("got %d pauses %v, want %d", len(got), got, len(max))
+		return
+	}
+	for i := range got {
+		if got[i] > max[i] {
+			t.Errorf("pause %d is %v, want at most %v", i, got[i], max[i])
+		}
+	}
+}
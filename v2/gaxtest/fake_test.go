@@ -0,0 +1,149 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gaxtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestFakeCallRetry(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	fake := NewFakeCall(
+		Fail[string](StatusError(codes.Unavailable, "unavailable")),
+		Fail[string](StatusError(codes.Unavailable, "unavailable")),
+		Succeed("done"),
+	)
+	var resp string
+	err := gax.Invoke(context.Background(), fake.Bind(&resp),
+		clock.CallOption(),
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Second, Multiplier: 2})
+		}))
+	if err != nil || resp != "done" {
+		t.Fatalf("got (%q, %v), want (done, nil)", resp, err)
+	}
+	AssertAttempts(t, fake, 3)
+	AssertPausesAtMost(t, clock, time.Second, 2*time.Second)
+
+	var total time.Duration
+	for _, p := range clock.Pauses() {
+		total += p
+	}
+	if got := clock.Now().Sub(time.Unix(0, 0)); got != total {
+		t.Errorf("clock advanced %v, want %v", got, total)
+	}
+}
+
+func TestClockWithFaultDelay(t *testing.T) {
+	// Injected latency is real, and is not recorded as a retry pause.
+	clock := NewClock(time.Unix(0, 0))
+	fake := NewFakeCall(
+		Fail[string](StatusError(codes.Unavailable, "unavailable")),
+		Succeed("done"),
+	)
+	var resp string
+	start := time.Now()
+	err := gax.Invoke(context.Background(), fake.Bind(&resp),
+		clock.CallOption(),
+		gax.WithFaults(gax.Fault{Probability: 1, Delay: 10 * time.Millisecond}),
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Second})
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("call took %v, want at least 20ms of injected latency", elapsed)
+	}
+	AssertAttempts(t, fake, 2)
+	AssertPausesAtMost(t, clock, time.Second)
+}
+
+func TestFakeCallRepeatsLastStep(t *testing.T) {
+	errFoo := errors.New("foo error")
+	fake := NewFakeCall(Fail[int](errFoo))
+	call := fake.Bind(nil)
+	for i := 0; i < 3; i++ {
+		if err := call(context.Background(), gax.CallSettings{}); err != errFoo {
+			t.Errorf("attempt %d: found error %v, want %v", i, err, errFoo)
+		}
+	}
+	AssertAttempts(t, fake, 3)
+
+	if err := NewFakeCall[int]().Bind(nil)(context.Background(), gax.CallSettings{}); err != nil {
+		t.Errorf("empty script: found error %v, want nil", err)
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	err := StatusError(codes.PermissionDenied, "denied", &errdetails.ErrorInfo{Reason: "SERVICE_DISABLED"})
+	apierr, ok := apierror.FromError(err)
+	if !ok || apierr.GRPCStatus().Code() != codes.PermissionDenied || apierr.Reason() != "SERVICE_DISABLED" {
+		t.Errorf("got %v, want PermissionDenied with reason SERVICE_DISABLED", err)
+	}
+}
+
+func TestHTTPErrorRetryAfter(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	fake := NewFakeCall(
+		Fail[int](HTTPError(http.StatusTooManyRequests, "slow down", http.Header{"Retry-After": []string{"3"}})),
+		Succeed(1),
+	)
+	err := gax.Invoke(context.Background(), fake.Bind(nil),
+		clock.CallOption(),
+		gax.WithRetry(func() gax.Retryer { return gax.OnHTTPCodes(gax.Backoff{}, http.StatusTooManyRequests) }))
+	if err != nil {
+		t.Fatalf("found error %v, want nil", err)
+	}
+	AssertPauses(t, clock, 3*time.Second)
+}
+
+func TestClockSleepCanceled(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := clock.Sleep(ctx, time.Second); err != context.Canceled {
+		t.Errorf("found error %v, want %v", err, context.Canceled)
+	}
+	AssertPauses(t, clock)
+	clock.Advance(time.Minute)
+	if got := clock.Now(); !got.Equal(time.Unix(60, 0)) {
+		t.Errorf("got %v after Advance, want %v", got, time.Unix(60, 0))
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+// Package gaxtest contains helpers for testing code built on gax and the
+// generated Go Client Libraries.
+package gaxtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/googleapi"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Session records APICalls to a file, or replays previously recorded ones,
+// giving hermetic tests for code built on generated clients. Wrap each
+// APICall with WrapCall.
+//
+// A Session is safe for concurrent use by multiple goroutines.
+type Session struct {
+	path   string
+	replay bool
+
+	mu      sync.Mutex
+	entries []*entry
+	// used marks the replayed entries, so that repeated identical calls are
+	// served in recorded order.
+	used []bool
+}
+
+// entry is a recorded call.
+type entry struct {
+	Method   string          `json:"method"`
+	Request  *anypb.Any      `json:"-"`
+	Response *anypb.Any      `json:"-"`
+	Err      *recordedError  `json:"error,omitempty"`
+	Duration time.Duration   `json:"duration"`
+	RawReq   json.RawMessage `json:"request"`
+	RawResp  json.RawMessage `json:"response,omitempty"`
+}
+
+// recordedError is the serialized form of an error returned by an APICall.
+// Exactly one of its fields is set.
+type recordedError struct {
+	// Status is a gRPC status, including its details.
+	Status json.RawMessage `json:"status,omitempty"`
+	// HTTP is a googleapi.Error.
+	HTTP *httpError `json:"http,omitempty"`
+	// Context is the message of context.Canceled or
+	// context.DeadlineExceeded.
+	Context string `json:"context,omitempty"`
+	// Message is the message of any other error.
+	Message string `json:"message,omitempty"`
+}
+
+// httpError is the serialized form of a googleapi.Error.
+type httpError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message,omitempty"`
+	Body    string      `json:"body,omitempty"`
+	Header  http.Header `json:"header,omitempty"`
+}
+
+// Record returns a Session that runs each wrapped APICall and records it.
+// The recording is written to path by Close.
+func Record(path string) *Session {
+	return &Session{path: path}
+}
+
+// Replay returns a Session that serves wrapped APICalls from the recording at
+// path, without running them.
+func Replay(path string) (*Session, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("gaxtest: parsing %s: %w", path, err)
+	}
+	for _, e := range entries {
+		e.Request = &anypb.Any{}
+		if err := protojson.Unmarshal(e.RawReq, e.Request); err != nil {
+			return nil, fmt.Errorf("gaxtest: parsing request of %s: %w", e.Method, err)
+		}
+		if len(e.RawResp) > 0 {
+			e.Response = &anypb.Any{}
+			if err := protojson.Unmarshal(e.RawResp, e.Response); err != nil {
+				return nil, fmt.Errorf("gaxtest: parsing response of %s: %w", e.Method, err)
+			}
+		}
+	}
+	return &Session{path: path, replay: true, entries: entries, used: make([]bool, len(entries))}, nil
+}
+
+// Close writes the recording of a recording Session. It is a no-op when
+// replaying.
+func (s *Session) Close() error {
+	if s.replay {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		var err error
+		if e.RawReq, err = protojson.Marshal(e.Request); err != nil {
+			return fmt.Errorf("gaxtest: serializing request of %s: %w", e.Method, err)
+		}
+		if e.Response != nil {
+			if e.RawResp, err = protojson.Marshal(e.Response); err != nil {
+				return fmt.Errorf("gaxtest: serializing response of %s: %w", e.Method, err)
+			}
+		}
+	}
+	b, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0644)
+}
+
+// WrapCall returns an APICall for method and req that is recorded or replayed
+// by s. resp must point to the variable that call stores its response in.
+//
+// When replaying, the first unused recording with the same method and an
+// equal request is served: its response is stored in resp, and its error is
+// returned. Recorded errors keep their gRPC status details or HTTP response,
+// so they are parsed by apierror as the originals were.
+func WrapCall[Resp proto.Message](s *Session, method string, req proto.Message, resp *Resp, call gax.APICall) gax.APICall {
+	if s.replay {
+		return func(context.Context, gax.CallSettings) error {
+			m, err := s.replayCall(method, req)
+			if m != nil && resp != nil {
+				r, ok := m.(Resp)
+				if !ok {
+					return fmt.Errorf("gaxtest: recorded response of %s is a %T, want %T", method, m, *resp)
+				}
+				*resp = r
+			}
+			return err
+		}
+	}
+	return func(ctx context.Context, settings gax.CallSettings) error {
+		start := time.Now()
+		err := call(ctx, settings)
+		d := time.Since(start)
+		var out proto.Message
+		if err == nil && resp != nil {
+			out = *resp
+		}
+		if rerr := s.record(method, req, out, err, d); rerr != nil {
+			return rerr
+		}
+		return err
+	}
+}
+
+// record appends a call to the recording.
+func (s *Session) record(method string, req, resp proto.Message, callErr error, d time.Duration) error {
+	e := &entry{Method: method, Duration: d}
+	var err error
+	if e.Request, err = anypb.New(req); err != nil {
+		return fmt.Errorf("gaxtest: recording request of %s: %w", method, err)
+	}
+	if resp != nil && resp.ProtoReflect().IsValid() {
+		if e.Response, err = anypb.New(resp); err != nil {
+			return fmt.Errorf("gaxtest: recording response of %s: %w", method, err)
+		}
+	}
+	if callErr != nil {
+		if e.Err, err = encodeError(callErr); err != nil {
+			return fmt.Errorf("gaxtest: recording error of %s: %w", method, err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+	return nil
+}
+
+// replayCall serves a call from the recording, returning the recorded
+// response, if any, and error.
+func (s *Session) replayCall(method string, req proto.Message) (proto.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if s.used[i] || e.Method != method {
+			continue
+		}
+		recorded, err := e.Request.UnmarshalNew()
+		if err != nil || !proto.Equal(recorded, req) {
+			continue
+		}
+		s.used[i] = true
+		var resp proto.Message
+		if e.Response != nil {
+			if resp, err = e.Response.UnmarshalNew(); err != nil {
+				return nil, fmt.Errorf("gaxtest: replaying response of %s: %w", method, err)
+			}
+		}
+		if e.Err != nil {
+			return resp, e.Err.decode()
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf("gaxtest: no recorded call to %s with request %v", method, req)
+}
+
+// encodeError serializes err.
+func encodeError(err error) (*recordedError, error) {
+	var herr *googleapi.Error
+	if errors.As(err, &herr) {
+		return &recordedError{HTTP: &httpError{Code: herr.Code, Message: herr.Message, Body: herr.Body, Header: herr.Header}}, nil
+	}
+	if st, ok := status.FromError(err); ok {
+		b, err := protojson.Marshal(st.Proto())
+		if err != nil {
+			return nil, err
+		}
+		return &recordedError{Status: b}, nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return &recordedError{Context: status.FromContextError(err).Code().String()}, nil
+	}
+	return &recordedError{Message: err.Error()}, nil
+}
+
+// decode reconstructs the recorded error.
+func (r *recordedError) decode() error {
+	switch {
+	case r.HTTP != nil:
+		return &googleapi.Error{Code: r.HTTP.Code, Message: r.HTTP.Message, Body: r.HTTP.Body, Header: r.HTTP.Header}
+	case r.Status != nil:
+		st := &spb.Status{}
+		if err := protojson.Unmarshal(r.Status, st); err != nil {
+			return fmt.Errorf("gaxtest: replaying status: %w", err)
+		}
+		return status.FromProto(st).Err()
+	case r.Context == "Canceled":
+		return context.Canceled
+	case r.Context != "":
+		return context.DeadlineExceeded
+	}
+	return errors.New(r.Message)
+}
@@ -0,0 +1,126 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gaxtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/googleapis/gax-go/v2"
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echo is a fake backend that upper-cases "ok" requests and fails others.
+func echo(req *wrapperspb.StringValue, resp **wrapperspb.StringValue) gax.APICall {
+	return func(context.Context, gax.CallSettings) error {
+		switch req.GetValue() {
+		case "ok":
+			*resp = wrapperspb.String("OK")
+			return nil
+		case "quota":
+			st, _ := status.New(codes.ResourceExhausted, "quota exhausted").WithDetails(&errdetails.ErrorInfo{Reason: "RATE_LIMIT_EXCEEDED"})
+			return st.Err()
+		case "http":
+			return &googleapi.Error{Code: http.StatusNotFound, Message: "not found", Header: http.Header{"X-Test": []string{"1"}}}
+		}
+		return errors.New("bad request")
+	}
+}
+
+func callSession(s *Session, value string, call func(*wrapperspb.StringValue, **wrapperspb.StringValue) gax.APICall) (*wrapperspb.StringValue, error) {
+	req := wrapperspb.String(value)
+	var resp *wrapperspb.StringValue
+	err := gax.Invoke(context.Background(), WrapCall(s, "Echo", req, &resp, call(req, &resp)))
+	return resp, err
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "echo.json")
+	rec := Record(path)
+	for _, v := range []string{"ok", "quota", "http", "bad"} {
+		callSession(rec, v, echo)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	rep, err := Replay(path)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	unreachable := func(*wrapperspb.StringValue, **wrapperspb.StringValue) gax.APICall {
+		return func(context.Context, gax.CallSettings) error {
+			t.Error("APICall ran while replaying")
+			return nil
+		}
+	}
+
+	resp, err := callSession(rep, "ok", unreachable)
+	if err != nil || !proto.Equal(resp, wrapperspb.String("OK")) {
+		t.Errorf("ok: got (%v, %v), want (OK, nil)", resp, err)
+	}
+
+	_, err = callSession(rep, "quota", unreachable)
+	apierr, ok := apierror.FromError(err)
+	if !ok || apierr.GRPCStatus().Code() != codes.ResourceExhausted || apierr.Reason() != "RATE_LIMIT_EXCEEDED" {
+		t.Errorf("quota: got %v, want ResourceExhausted with reason", err)
+	}
+
+	_, err = callSession(rep, "http", unreachable)
+	var herr *googleapi.Error
+	if !errors.As(err, &herr) || herr.Code != http.StatusNotFound || herr.Header.Get("X-Test") != "1" {
+		t.Errorf("http: got %v, want googleapi.Error 404", err)
+	}
+
+	_, err = callSession(rep, "bad", unreachable)
+	if err == nil || err.Error() != "bad request" {
+		t.Errorf("bad: got %v, want bad request", err)
+	}
+
+	// Each recording is served once.
+	if _, err := callSession(rep, "ok", unreachable); err == nil {
+		t.Errorf("replaying an exhausted recording: got nil error")
+	}
+}
+
+func TestReplayMissingFile(t *testing.T) {
+	if _, err := Replay(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("got nil error for missing recording")
+	}
+}
@@ -35,7 +35,9 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/googleapis/gax-go/v2/callctx"
@@ -154,6 +156,51 @@ func BuildHeaders(ctx context.Context, keyvals ...string) http.Header {
 	return http.Header(insertMetadata(ctx, keyvals...))
 }
 
+// WithServerTimeoutHeader is for use by the Google Cloud Libraries only.
+//
+// WithServerTimeoutHeader returns a copy of h with the time remaining until
+// the deadline of ctx added as the x-server-timeout header, unless h already
+// has a value for it or ctx has no deadline. This lets HTTP servers abandon
+// work that the client has given up on, much like the grpc-timeout header
+// does for gRPC.
+//
+// It must be called inside the APICall passed to Invoke, with the context of
+// the attempt, so that the deadline set by WithTimeout is included and the
+// value is recomputed on every retry. h is typically the result of
+// BuildHeaders, which is computed once before Invoke and is not modified.
+func WithServerTimeoutHeader(ctx context.Context, h http.Header) http.Header {
+	out := h.Clone()
+	if out == nil {
+		out = http.Header{}
+	}
+	if len(out[serverTimeoutHeader]) > 0 || len(out.Values(serverTimeoutHeader)) > 0 {
+		return out
+	}
+	if v, ok := serverTimeout(ctx); ok {
+		out[serverTimeoutHeader] = []string{v}
+	}
+	return out
+}
+
+// serverTimeoutHeader is the header used to propagate the remaining client
+// deadline to HTTP servers.
+const serverTimeoutHeader = "x-server-timeout"
+
+// serverTimeout returns the time remaining until the deadline of ctx,
+// formatted as fractional seconds with millisecond precision. It returns false
+// if ctx has no deadline or the deadline has already passed.
+func serverTimeout(ctx context.Context) (string, bool) {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return "", false
+	}
+	d := time.Until(dl)
+	if d <= 0 {
+		return "", false
+	}
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64), true
+}
+
 func insertMetadata(ctx context.Context, keyvals ...string) metadata.MD {
 	if len(keyvals)%2 != 0 {
 		panic(fmt.Sprintf("gax: an even number of key value pairs must be provided, got %d", len(keyvals)))
@@ -32,11 +32,15 @@ package gax
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googleapis/gax-go/v2/callctx"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestXGoogHeader(t *testing.T) {
@@ -163,3 +167,119 @@ func TestBuildHeaders(t *testing.T) {
 		t.Errorf("InsertMetadata(ctx, %q) mismatch (-want +got):\n%s", keyvals, diff)
 	}
 }
+
+func TestWithServerTimeoutHeader(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, tst := range []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		keyvals []string
+		want    []string
+		wantMin float64
+		wantMax float64
+	}{
+		{
+			name: "no_deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.Background(), func() {}
+			},
+		},
+		{
+			name: "expired_deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return expired, func() {}
+			},
+		},
+		{
+			name: "remaining_deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Second)
+			},
+			wantMin: 9,
+			wantMax: 10,
+		},
+		{
+			name: "explicit_value",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Second)
+			},
+			keyvals: []string{"x-server-timeout", "5.000"},
+			want:    []string{"5.000"},
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			ctx, cancel := tst.ctx()
+			defer cancel()
+
+			h := BuildHeaders(ctx, tst.keyvals...)
+			got := WithServerTimeoutHeader(ctx, h)["x-server-timeout"]
+			if diff := cmp.Diff(tst.keyvals != nil, len(h["x-server-timeout"]) > 0); diff != "" {
+				t.Errorf("input header modified (-want +got):\n%s", diff)
+			}
+			if tst.wantMax == 0 {
+				if diff := cmp.Diff(tst.want, got); diff != "" {
+					t.Errorf("x-server-timeout mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %q, want a single value", got)
+			}
+			secs, err := strconv.ParseFloat(got[0], 64)
+			if err != nil {
+				t.Fatalf("ParseFloat(%q): %v", got[0], err)
+			}
+			if secs < tst.wantMin || secs > tst.wantMax {
+				t.Errorf("got %v, want between %v and %v", secs, tst.wantMin, tst.wantMax)
+			}
+		})
+	}
+}
+
+func TestInvokeServerTimeoutHeader(t *testing.T) {
+	ctx := context.Background()
+	// Headers are built once, before Invoke applies WithTimeout.
+	h := BuildHeaders(ctx, "key_1", "val_1")
+	if got := h["x-server-timeout"]; got != nil {
+		t.Fatalf("BuildHeaders() x-server-timeout = %q, want none", got)
+	}
+
+	var timeouts []float64
+	err := Invoke(ctx, func(ctx context.Context, _ CallSettings) error {
+		v := WithServerTimeoutHeader(ctx, h)["x-server-timeout"]
+		if len(v) != 1 {
+			t.Fatalf("got x-server-timeout %q, want a single value", v)
+		}
+		secs, err := strconv.ParseFloat(v[0], 64)
+		if err != nil {
+			t.Fatalf("ParseFloat(%q): %v", v[0], err)
+		}
+		timeouts = append(timeouts, secs)
+		if len(timeouts) == 1 {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	},
+		WithTimeout(10*time.Second),
+		WithRetry(func() Retryer {
+			return OnCodes([]codes.Code{codes.Unavailable}, Backoff{})
+		}),
+		WithSleep(func(context.Context, time.Duration) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeouts) != 2 {
+		t.Fatalf("got %d attempts, want 2", len(timeouts))
+	}
+	if timeouts[0] > 10 || timeouts[0] < 9 {
+		t.Errorf("first attempt x-server-timeout = %v, want between 9 and 10", timeouts[0])
+	}
+	if timeouts[1] > timeouts[0]-0.05 {
+		t.Errorf("retry x-server-timeout = %v, want at most %v", timeouts[1], timeouts[0]-0.05)
+	}
+}
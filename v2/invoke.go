@@ -31,8 +31,8 @@ package gax
 
 import (
 	"context"
+	"io"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/googleapis/gax-go/v2/apierror"
@@ -58,9 +58,148 @@ func Invoke(ctx context.Context, call APICall, opts ...CallOption) error {
 	for _, opt := range opts {
 		opt.Resolve(&settings)
 	}
+	if settings.sleep != nil {
+		return invoke(ctx, call, settings, settings.sleep)
+	}
 	return invoke(ctx, call, settings, Sleep)
 }
 
+// InvokeValue calls the given function, performing retries as specified by
+// opts, if any, and returns the value produced by the successful attempt.
+// Values from failed attempts are discarded, so a partial result of an earlier
+// attempt can never be returned. To also treat values that fail a check as
+// failed attempts, wrap call with ValidateResult.
+func InvokeValue[T any](ctx context.Context, call func(context.Context, CallSettings) (T, error), opts ...CallOption) (T, error) {
+	var resp T
+	err := Invoke(ctx, func(ctx context.Context, settings CallSettings) error {
+		v, err := call(ctx, settings)
+		if err != nil {
+			return err
+		}
+		resp = v
+		return nil
+	}, opts...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return resp, nil
+}
+
+// ValidateResult returns a function for InvokeValue that calls call and
+// checks the value of each successful attempt with validate. If validate
+// returns an error, the attempt is treated as having failed with that error,
+// which is subject to the configured Retryer.
+func ValidateResult[T any](call func(context.Context, CallSettings) (T, error), validate func(T) error) func(context.Context, CallSettings) (T, error) {
+	return func(ctx context.Context, settings CallSettings) (T, error) {
+		v, err := call(ctx, settings)
+		if err == nil {
+			err = validate(v)
+		}
+		return v, err
+	}
+}
+
+// InvokeStream opens a stream with the given function, retrying failures to
+// establish it as specified by opts, if any, and returns the stream of the
+// successful attempt. To also retry failures to receive the first message,
+// open should receive it and keep it for the caller.
+//
+// Unlike Invoke, a timeout set by WithTimeout only bounds establishing the
+// stream, including any pauses between attempts, so that the stream itself
+// may outlive it. If metrics are enabled, the time taken is recorded as the
+// stream establishment duration rather than the request duration.
+//
+// When a timeout bounds establishment, each attempt opens the stream with a
+// context that is canceled if the timeout expires first. Once the stream is
+// established, that context is released when the stream's own context is
+// done, if S has a Context method as gRPC client streams do, and otherwise
+// only when ctx is done. Callers opening many streams of other types from a
+// long-lived ctx should give each a context of its own. A stream that open
+// returns after the timeout has expired is discarded, and is closed first if
+// S has a Close or CloseSend method.
+func InvokeStream[S any](ctx context.Context, open func(context.Context, CallSettings) (S, error), opts ...CallOption) (S, error) {
+	var settings CallSettings
+	for _, opt := range opts {
+		opt.Resolve(&settings)
+	}
+	var sp sleeper = Sleep
+	if settings.sleep != nil {
+		sp = settings.sleep
+	}
+	settings.stream = true
+
+	var deadline time.Time
+	if _, ok := ctx.Deadline(); !ok && settings.timeout != 0 {
+		deadline = time.Now().Add(settings.timeout)
+		// The deadline is enforced per attempt below, without canceling the
+		// context of the established stream.
+		settings.timeout = 0
+		bounded := sp
+		sp = func(ctx context.Context, d time.Duration) error {
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+			return bounded(ctx, d)
+		}
+	}
+
+	var stream S
+	err := invoke(ctx, func(ctx context.Context, settings CallSettings) error {
+		if deadline.IsZero() {
+			s, err := open(ctx, settings)
+			if err != nil {
+				return err
+			}
+			stream = s
+			return nil
+		}
+		ctx, cancel := context.WithCancelCause(ctx)
+		timer := time.AfterFunc(time.Until(deadline), func() { cancel(context.DeadlineExceeded) })
+		s, err := open(ctx, settings)
+		if !timer.Stop() {
+			// The stream, if any, has been canceled by the deadline.
+			if err == nil {
+				closeStream(s)
+			}
+			return context.DeadlineExceeded
+		}
+		if err != nil {
+			cancel(err)
+			return err
+		}
+		releaseWithStream(s, cancel)
+		stream = s
+		return nil
+	}, settings, sp)
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+	return stream, nil
+}
+
+// closeStream closes a stream that was established too late to be returned,
+// if it has a Close or CloseSend method.
+func closeStream(s any) {
+	switch s := s.(type) {
+	case io.Closer:
+		s.Close()
+	case interface{ CloseSend() error }:
+		s.CloseSend()
+	}
+}
+
+// releaseWithStream arranges for cancel, which cancels the context the
+// stream was opened with, to be called once the stream's own context is done,
+// if it has a Context method as gRPC client streams do. This releases the
+// context from its parent when the stream ends rather than when the parent
+// is done.
+func releaseWithStream(s any, cancel context.CancelCauseFunc) {
+	if s, ok := s.(interface{ Context() context.Context }); ok {
+		context.AfterFunc(s.Context(), func() { cancel(context.Canceled) })
+	}
+}
+
 // Sleep is similar to time.Sleep, but it can be interrupted by ctx.Done() closing.
 // If interrupted, Sleep returns ctx.Err().
 func Sleep(ctx context.Context, d time.Duration) error {
@@ -78,8 +217,29 @@ type sleeper func(ctx context.Context, d time.Duration) error
 
 // invoke implements Invoke, taking an additional sleeper argument for testing.
 func invoke(ctx context.Context, call APICall, settings CallSettings, sp sleeper) (err error) {
+	if settings.cache != nil {
+		return invokeCached(ctx, call, settings, sp)
+	}
+
 	var retryer Retryer
 
+	faults := envFaults()
+	faults = append(faults[:len(faults):len(faults)], settings.faults...)
+	if len(faults) > 0 {
+		call = withFaults(call, faults)
+	}
+
+	// Registered first so that it runs last, after any metrics have been
+	// recorded against the unwrapped error.
+	var attempts []RetryAttempt
+	if settings.retryError {
+		defer func() {
+			if err != nil {
+				err = &RetryError{Attempts: attempts, err: err}
+			}
+		}()
+	}
+
 	// Only use the value provided via WithTimeout if the context doesn't
 	// already have a deadline. This is important for backwards compatibility if
 	// the user already set a deadline on the context given to Invoke.
@@ -105,17 +265,33 @@ func invoke(ctx context.Context, call APICall, settings CallSettings, sp sleeper
 		if tracingEnabled {
 			ctxToUse = withRetryCount(ctx, retryCount)
 		}
+		var endpoint string
+		if settings.endpoints != nil {
+			endpoint = settings.endpoints.Current()
+			ctxToUse = withEndpoint(ctxToUse, endpoint)
+			if td := ExtractTransportTelemetry(ctx); td != nil {
+				td.SetServerAddress(endpoint)
+			}
+		}
+		start := time.Now()
 		err = call(ctxToUse, settings)
+		if settings.endpoints != nil {
+			settings.endpoints.Report(endpoint, err)
+		}
 		if err == nil {
 			return nil
 		}
-		// Never retry permanent certificate errors. (e.x. if ca-certificates
-		// are not installed). We should only make very few, targeted
-		// exceptions: many (other) status=Unavailable should be retried, such
-		// as if there's a network hiccup, or the internet goes out for a
-		// minute. This is also why here we are doing string parsing instead of
-		// simply making Unavailable a non-retried code elsewhere.
-		if strings.Contains(err.Error(), "x509: certificate signed by unknown authority") {
+		if settings.retryError {
+			attempts = append(attempts, RetryAttempt{
+				Err:         err,
+				Start:       start,
+				Duration:    time.Since(start),
+				ResendCount: retryCount,
+			})
+		}
+		// Never retry permanent errors (e.x. if ca-certificates are not
+		// installed). See RegisterPermanentErrorClassifier.
+		if IsPermanentError(err) {
 			return err
 		}
 		if apierr, ok := apierror.FromError(err); ok {
@@ -131,9 +307,14 @@ func invoke(ctx context.Context, call APICall, settings CallSettings, sp sleeper
 				return err
 			}
 		}
-		if d, ok := retryer.Retry(err); !ok {
+		d, ok := retryer.Retry(err)
+		if !ok {
 			return err
-		} else if err = sp(ctx, d); err != nil {
+		}
+		if settings.retryError {
+			attempts[len(attempts)-1].Pause = d
+		}
+		if err = sp(ctx, d); err != nil {
 			return err
 		}
 		retryCount++
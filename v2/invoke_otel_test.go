@@ -240,3 +240,34 @@ func TestInvokeWithMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestInvokeStreamWithMetrics(t *testing.T) {
+	t.Setenv("GOOGLE_SDK_GO_EXPERIMENTAL_METRICS", "true")
+	TestOnlyResetIsFeatureEnabled()
+	defer TestOnlyResetIsFeatureEnabled()
+
+	reader := metric.NewManualReader()
+	provider := metric.NewMeterProvider(metric.WithReader(reader))
+	cm := NewClientMetrics(WithMeterProvider(provider))
+
+	_, err := InvokeStream(context.Background(), func(context.Context, CallSettings) (int, error) {
+		return 1, nil
+	}, WithClientMetrics(cm))
+	if err != nil {
+		t.Fatalf("InvokeStream() error = %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var names []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names = append(names, m.Name)
+		}
+	}
+	if diff := cmp.Diff([]string{streamMetricName}, names); diff != "" {
+		t.Errorf("recorded metrics mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -310,3 +310,154 @@ func TestInvokeRetryCount(t *testing.T) {
 		})
 	}
 }
+
+func TestInvokeValue(t *testing.T) {
+	calls := 0
+	call := func(context.Context, CallSettings) (string, error) {
+		calls++
+		if calls < 3 {
+			// A partial result alongside an error must be discarded.
+			return "partial", errors.New("retry")
+		}
+		return "done", nil
+	}
+	got, err := InvokeValue(context.Background(), call,
+		WithSleep(func(context.Context, time.Duration) error { return nil }),
+		WithRetry(func() Retryer { return boolRetryer(true) }))
+	if err != nil || got != "done" {
+		t.Errorf("got (%q, %v), want (done, nil)", got, err)
+	}
+}
+
+func TestInvokeValueError(t *testing.T) {
+	apiErr := errors.New("foo error")
+	got, err := InvokeValue(context.Background(), func(context.Context, CallSettings) (string, error) {
+		return "partial", apiErr
+	})
+	if err != apiErr || got != "" {
+		t.Errorf("got (%q, %v), want (\"\", %v)", got, err, apiErr)
+	}
+}
+
+func TestInvokeValueValidator(t *testing.T) {
+	errIncomplete := errors.New("incomplete")
+	calls := 0
+	call := ValidateResult(func(context.Context, CallSettings) (int, error) {
+		calls++
+		return calls, nil
+	}, func(v int) error {
+		if v < 2 {
+			return errIncomplete
+		}
+		return nil
+	})
+
+	got, err := InvokeValue(context.Background(), call,
+		WithSleep(func(context.Context, time.Duration) error { return nil }),
+		WithRetry(func() Retryer { return boolRetryer(true) }))
+	if err != nil || got != 2 {
+		t.Errorf("got (%d, %v), want (2, nil)", got, err)
+	}
+
+	calls = 0
+	if got, err := InvokeValue(context.Background(), call); err != errIncomplete || got != 0 {
+		t.Errorf("without retries: got (%d, %v), want (0, %v)", got, err, errIncomplete)
+	}
+}
+
+func TestInvokeStream(t *testing.T) {
+	type stream struct{ ctx context.Context }
+	calls := 0
+	open := func(ctx context.Context, _ CallSettings) (*stream, error) {
+		calls++
+		if calls < 2 {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+		return &stream{ctx: ctx}, nil
+	}
+	s, err := InvokeStream(context.Background(), open,
+		WithTimeout(20*time.Millisecond),
+		WithSleep(func(context.Context, time.Duration) error { return nil }),
+		WithRetry(func() Retryer { return OnCodes([]codes.Code{codes.Unavailable}, Backoff{}) }))
+	if err != nil {
+		t.Fatalf("found error %v, want nil", err)
+	}
+	if calls != 2 {
+		t.Errorf("opened %d times, want 2", calls)
+	}
+	// The timeout only applies to establishing the stream.
+	time.Sleep(40 * time.Millisecond)
+	if err := s.ctx.Err(); err != nil {
+		t.Errorf("stream context done after establishment timeout: %v", err)
+	}
+}
+
+// ctxStream is a stream with a context of its own, like a gRPC client stream.
+type ctxStream struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+}
+
+func (s *ctxStream) Context() context.Context { return s.ctx }
+
+func (s *ctxStream) CloseSend() error {
+	s.closed = true
+	return nil
+}
+
+func TestInvokeStreamReleasesContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var openCtx context.Context
+	open := func(ctx context.Context, _ CallSettings) (*ctxStream, error) {
+		openCtx = ctx
+		sctx, scancel := context.WithCancel(ctx)
+		return &ctxStream{ctx: sctx, cancel: scancel}, nil
+	}
+	s, err := InvokeStream(parent, open, WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("found error %v, want nil", err)
+	}
+	if err := openCtx.Err(); err != nil {
+		t.Fatalf("stream context done while the stream is open: %v", err)
+	}
+	// Ending the stream releases the context it was opened with.
+	s.cancel()
+	select {
+	case <-openCtx.Done():
+	case <-time.After(time.Second):
+		t.Error("context given to open not canceled after the stream ended")
+	}
+	if err := parent.Err(); err != nil {
+		t.Errorf("parent context done: %v", err)
+	}
+}
+
+func TestInvokeStreamClosesLateStream(t *testing.T) {
+	var s *ctxStream
+	open := func(ctx context.Context, _ CallSettings) (*ctxStream, error) {
+		// Establish the stream regardless of the deadline.
+		time.Sleep(20 * time.Millisecond)
+		s = &ctxStream{ctx: ctx}
+		return s, nil
+	}
+	got, err := InvokeStream(context.Background(), open, WithTimeout(time.Millisecond))
+	if err != context.DeadlineExceeded || got != nil {
+		t.Errorf("got (%v, %v), want (nil, %v)", got, err, context.DeadlineExceeded)
+	}
+	if s == nil || !s.closed {
+		t.Error("stream established after the deadline was not closed")
+	}
+}
+
+func TestInvokeStreamTimeout(t *testing.T) {
+	open := func(ctx context.Context, _ CallSettings) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	_, err := InvokeStream(context.Background(), open, WithTimeout(time.Millisecond))
+	if err != context.DeadlineExceeded {
+		t.Errorf("found error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
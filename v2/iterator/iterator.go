@@ -34,8 +34,12 @@
 package iterator
 
 import (
+	"context"
+	"errors"
 	"iter"
+	"reflect"
 
+	"github.com/googleapis/gax-go/v2"
 	otherit "google.golang.org/api/iterator"
 )
 
@@ -52,7 +56,7 @@ func RangeAdapter[T any](next func() (T, error)) iter.Seq2[T, error] {
 			}
 			var resp T
 			resp, err = next()
-			if err == otherit.Done {
+			if errors.Is(err, otherit.Done) {
 				return
 			}
 			if !yield(resp, err) {
@@ -61,3 +65,238 @@ func RangeAdapter[T any](next func() (T, error)) iter.Seq2[T, error] {
 		}
 	}
 }
+
+// ErrorPolicy determines how RangeAdapterContext handles an error returned by
+// the client iterator.
+type ErrorPolicy int
+
+const (
+	// StopOnError yields the error and ends the iteration.
+	StopOnError ErrorPolicy = iota
+
+	// SkipOnError drops errors that the Retryer accepts and moves on to the
+	// next item. Other errors are handled as with StopOnError.
+	SkipOnError
+
+	// RetryOnError pauses as directed by the Retryer and calls the client
+	// iterator again for errors that the Retryer accepts. Other errors are
+	// handled as with StopOnError.
+	RetryOnError
+)
+
+// RangeOptions configures RangeAdapterContext.
+//
+// This is for internal use only.
+type RangeOptions struct {
+	// ErrorPolicy determines how errors are handled. The default is
+	// StopOnError.
+	ErrorPolicy ErrorPolicy
+
+	// Retryer returns the Retryer deciding which errors are skipped or
+	// retried, and for how long. A new Retryer is used for each item. It
+	// must be set unless ErrorPolicy is StopOnError.
+	Retryer func() gax.Retryer
+}
+
+// RangeAdapterContext is like RangeAdapter, but stops by yielding ctx.Err()
+// once ctx is done, and handles errors according to opts.
+//
+// Skipping and retrying only help with client iterators that can recover
+// from an error. Iterators built on iterator.PageInfo, as in the generated
+// clients, return the same error on every call after a failure. When next
+// returns the very same error twice in a row, RangeAdapterContext yields it
+// and stops, whatever the ErrorPolicy.
+//
+// This is for internal use only.
+func RangeAdapterContext[T any](ctx context.Context, next func() (T, error), opts RangeOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var retryer gax.Retryer
+		var lastErr error
+		for {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			resp, err := next()
+			if errors.Is(err, otherit.Done) {
+				return
+			}
+			if err == nil {
+				retryer, lastErr = nil, nil
+				if !yield(resp, nil) {
+					return
+				}
+				continue
+			}
+			if opts.ErrorPolicy != StopOnError && !sameError(err, lastErr) {
+				lastErr = err
+				if retryer == nil {
+					retryer = opts.Retryer()
+				}
+				if pause, ok := retryer.Retry(err); ok {
+					if opts.ErrorPolicy == RetryOnError {
+						// A canceled sleep is reported at the top of
+						// the loop.
+						gax.Sleep(ctx, pause)
+					}
+					continue
+				}
+			}
+			yield(resp, err)
+			return
+		}
+	}
+}
+
+// sameError reports whether a and b are the same error value, as returned
+// again by a client iterator that cannot recover from it.
+func sameError(a, b error) bool {
+	if a == nil || b == nil || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// PageAdapter transforms a client iterator into an [iter.Seq2] over pages of
+// items, so that callers can checkpoint their progress with NextPageToken and
+// later resume from it.
+//
+// This is for internal use only.
+type PageAdapter[T any] struct {
+	pager *otherit.Pager
+	token string
+}
+
+// NewPageAdapter returns a PageAdapter that reads pages of pageSize items from
+// it, starting at pageToken. Pass the empty string to start at the beginning,
+// or a token previously returned by NextPageToken to resume. The items of it
+// must be of type T, and Next must not be called on it.
+//
+// This is for internal use only.
+func NewPageAdapter[T any](it otherit.Pageable, pageSize int, pageToken string) *PageAdapter[T] {
+	return &PageAdapter[T]{
+		pager: otherit.NewPager(it, pageSize, pageToken),
+		token: pageToken,
+	}
+}
+
+// All returns an iterator over the remaining pages. Iteration stops after the
+// first error.
+func (a *PageAdapter[T]) All() iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		for {
+			var items []T
+			token, err := a.pager.NextPage(&items)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			a.token = token
+			if len(items) == 0 && token == "" {
+				return
+			}
+			if !yield(items, nil) || token == "" {
+				return
+			}
+		}
+	}
+}
+
+// NextPageToken returns the token of the page following the last page
+// yielded by All, or the starting token if no page has been yielded yet. It
+// is empty once the last page has been yielded.
+func (a *PageAdapter[T]) NextPageToken() string {
+	return a.token
+}
+
+// morePages is the page token reported by a NextIterator until its sequence
+// is exhausted.
+const morePages = "more"
+
+// NextIterator is a client iterator backed by an [iter.Seq2]. It is created
+// by NextAdapter.
+//
+// This is for internal use only.
+type NextIterator[T any] struct {
+	pull     func() (T, error, bool)
+	stop     func()
+	err      error
+	items    []T
+	pageInfo *otherit.PageInfo
+	nextFunc func() error
+}
+
+// NextAdapter transforms an [iter.Seq2] into a client iterator, with the Next
+// and PageInfo methods of the iterators in the generated clients. Call Stop
+// if the iterator is not read until Next returns iterator.Done.
+//
+// The page tokens reported through PageInfo are opaque markers, and cannot be
+// used to resume the iteration.
+//
+// This is for internal use only.
+func NextAdapter[T any](seq iter.Seq2[T, error]) *NextIterator[T] {
+	it := &NextIterator[T]{}
+	it.pull, it.stop = iter.Pull2(seq)
+	it.pageInfo, it.nextFunc = otherit.NewPageInfo(it.fetch, it.bufLen, it.takeBuf)
+	return it
+}
+
+// PageInfo supports pagination. See the google.golang.org/api/iterator
+// package for details.
+func (it *NextIterator[T]) PageInfo() *otherit.PageInfo {
+	return it.pageInfo
+}
+
+// Next returns the next item. Its second return value is iterator.Done if
+// there are no more items, or the error yielded by the sequence. Once Next
+// returns an error, every subsequent call returns the same error.
+func (it *NextIterator[T]) Next() (T, error) {
+	var item T
+	if err := it.nextFunc(); err != nil {
+		return item, err
+	}
+	item = it.items[0]
+	it.items = it.items[1:]
+	return item, nil
+}
+
+// Stop releases the resources held by the sequence. After Stop, Next returns
+// iterator.Done once the buffered items have been read.
+func (it *NextIterator[T]) Stop() {
+	it.stop()
+}
+
+// fetch moves up to pageSize items from the sequence into the buffer. An
+// error is held back until the items read before it have been consumed.
+func (it *NextIterator[T]) fetch(pageSize int, _ string) (string, error) {
+	if it.err != nil {
+		return "", it.err
+	}
+	for range max(pageSize, 1) {
+		item, err, ok := it.pull()
+		if !ok {
+			return "", nil
+		}
+		if err != nil {
+			it.err = err
+			it.stop()
+			if len(it.items) == 0 {
+				return "", err
+			}
+			break
+		}
+		it.items = append(it.items, item)
+	}
+	return morePages, nil
+}
+
+func (it *NextIterator[T]) bufLen() int {
+	return len(it.items)
+}
+
+func (it *NextIterator[T]) takeBuf() interface{} {
+	b := it.items
+	it.items = nil
+	return b
+}
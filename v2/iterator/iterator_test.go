@@ -0,0 +1,395 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gax-go/v2"
+	otherit "google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// intIterator is a client iterator in the style of the generated clients,
+// backed by a fakeServer.
+type intIterator struct {
+	items    []int
+	pageInfo *otherit.PageInfo
+	nextFunc func() error
+}
+
+func newIntIterator(s *fakeServer) *intIterator {
+	it := &intIterator{}
+	fetch := func(pageSize int, pageToken string) (string, error) {
+		req := &listRequest{pageToken: pageToken, pageSize: pageSize}
+		resp, err := s.list(context.Background(), req, gax.CallSettings{})
+		if err != nil {
+			return "", err
+		}
+		it.items = append(it.items, resp.items...)
+		return resp.nextPageToken, nil
+	}
+	bufLen := func() int { return len(it.items) }
+	takeBuf := func() interface{} {
+		b := it.items
+		it.items = nil
+		return b
+	}
+	it.pageInfo, it.nextFunc = otherit.NewPageInfo(fetch, bufLen, takeBuf)
+	return it
+}
+
+func (it *intIterator) PageInfo() *otherit.PageInfo { return it.pageInfo }
+
+func (it *intIterator) Next() (int, error) {
+	if err := it.nextFunc(); err != nil {
+		return 0, err
+	}
+	item := it.items[0]
+	it.items = it.items[1:]
+	return item, nil
+}
+
+func TestRangeAdapter(t *testing.T) {
+	it := newIntIterator(&fakeServer{n: 5, pageSize: 2})
+	var got []int
+	for v, err := range RangeAdapter(it.Next) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	if diff := cmp.Diff([]int{0, 1, 2, 3, 4}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPageAdapter(t *testing.T) {
+	a := NewPageAdapter[int](newIntIterator(&fakeServer{n: 5, pageSize: 10}), 2, "")
+	var pages [][]int
+	var tokens []string
+	for page, err := range a.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page)
+		tokens = append(tokens, a.NextPageToken())
+	}
+	if diff := cmp.Diff([][]int{{0, 1}, {2, 3}, {4}}, pages); diff != "" {
+		t.Errorf("pages mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"2", "4", ""}, tokens); diff != "" {
+		t.Errorf("tokens mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPageAdapterResume(t *testing.T) {
+	s := &fakeServer{n: 6, pageSize: 10}
+	a := NewPageAdapter[int](newIntIterator(s), 2, "")
+	for _, err := range a.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		break
+	}
+	token := a.NextPageToken()
+	if token != "2" {
+		t.Fatalf("NextPageToken() = %q, want %q", token, "2")
+	}
+
+	var got []int
+	for page, err := range NewPageAdapter[int](newIntIterator(s), 2, token).All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page...)
+	}
+	if diff := cmp.Diff([]int{2, 3, 4, 5}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPageAdapterError(t *testing.T) {
+	it := newIntIterator(&fakeServer{})
+	apiErr := errors.New("foo error")
+	it.pageInfo, it.nextFunc = otherit.NewPageInfo(
+		func(int, string) (string, error) { return "", apiErr },
+		func() int { return 0 },
+		func() interface{} { return []int(nil) })
+	var errs []error
+	for _, err := range NewPageAdapter[int](it, 2, "").All() {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] != apiErr {
+		t.Errorf("got errors %v, want [%v]", errs, apiErr)
+	}
+}
+
+// flakyNext returns a Next-style function over the integers [0, n) that
+// fails with err, wrapped in a new error each time, on the calls listed in
+// failAt.
+func flakyNext(n int, err error, failAt ...int) (next func() (int, error), calls *int) {
+	calls = new(int)
+	item := 0
+	return func() (int, error) {
+		call := *calls
+		*calls++
+		if slices.Contains(failAt, call) {
+			return 0, fmt.Errorf("call %d: %w", call, err)
+		}
+		if item == n {
+			return 0, fmt.Errorf("wrapped: %w", otherit.Done)
+		}
+		item++
+		return item - 1, nil
+	}, calls
+}
+
+func TestRangeAdapterContext(t *testing.T) {
+	transient := status.Error(codes.Unavailable, "unavailable")
+	permanent := status.Error(codes.PermissionDenied, "denied")
+	retryer := func() gax.Retryer {
+		return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Nanosecond})
+	}
+	for _, tst := range []struct {
+		name      string
+		opts      RangeOptions
+		err       error
+		failAt    []int
+		want      []int
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "done_wrapped",
+			want:      []int{0, 1, 2},
+			wantCalls: 4,
+		},
+		{
+			name:      "stop",
+			err:       transient,
+			failAt:    []int{1},
+			want:      []int{0},
+			wantErr:   transient,
+			wantCalls: 2,
+		},
+		{
+			name:      "skip",
+			opts:      RangeOptions{ErrorPolicy: SkipOnError, Retryer: retryer},
+			err:       transient,
+			failAt:    []int{1, 3},
+			want:      []int{0, 1, 2},
+			wantCalls: 6,
+		},
+		{
+			name:      "retry",
+			opts:      RangeOptions{ErrorPolicy: RetryOnError, Retryer: retryer},
+			err:       transient,
+			failAt:    []int{1, 2},
+			want:      []int{0, 1, 2},
+			wantCalls: 6,
+		},
+		{
+			name:      "retry_permanent",
+			opts:      RangeOptions{ErrorPolicy: RetryOnError, Retryer: retryer},
+			err:       permanent,
+			failAt:    []int{1},
+			want:      []int{0},
+			wantErr:   permanent,
+			wantCalls: 2,
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			next, calls := flakyNext(3, tst.err, tst.failAt...)
+			var got []int
+			var gotErr error
+			for v, err := range RangeAdapterContext(context.Background(), next, tst.opts) {
+				if err != nil {
+					gotErr = err
+					continue
+				}
+				got = append(got, v)
+			}
+			if !errors.Is(gotErr, tst.wantErr) {
+				t.Errorf("error = %v, want %v", gotErr, tst.wantErr)
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+			if *calls != tst.wantCalls {
+				t.Errorf("got %d calls, want %d", *calls, tst.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRangeAdapterContextPageInfo(t *testing.T) {
+	// A client iterator built on PageInfo keeps returning its first error,
+	// even though the server would succeed on the next attempt.
+	retryer := func() gax.Retryer {
+		return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{Initial: time.Nanosecond})
+	}
+	for _, policy := range []ErrorPolicy{SkipOnError, RetryOnError} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		s := &fakeServer{n: 5, pageSize: 2, failures: 1}
+		it := newIntIterator(s)
+		var errs []error
+		for _, err := range RangeAdapterContext(ctx, it.Next, RangeOptions{ErrorPolicy: policy, Retryer: retryer}) {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) != 1 || status.Code(errs[0]) != codes.Unavailable {
+			t.Errorf("policy %d: got errors %v, want a single Unavailable error", policy, errs)
+		}
+		if s.attempts != 1 {
+			t.Errorf("policy %d: got %d fetches, want 1", policy, s.attempts)
+		}
+	}
+}
+
+func TestRangeAdapterContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	next, calls := flakyNext(10, nil)
+	var got []int
+	var gotErr error
+	for v, err := range RangeAdapterContext(ctx, next, RangeOptions{}) {
+		if err != nil {
+			gotErr = err
+			continue
+		}
+		got = append(got, v)
+		if v == 1 {
+			cancel()
+		}
+	}
+	if gotErr != context.Canceled {
+		t.Errorf("error = %v, want %v", gotErr, context.Canceled)
+	}
+	if len(got) != 2 || *calls != 2 {
+		t.Errorf("got items %v after %d calls, want 2 items after 2 calls", got, *calls)
+	}
+}
+
+func TestNextAdapter(t *testing.T) {
+	it := NextAdapter(ints(5, -1))
+	var got []int
+	for {
+		v, err := it.Next()
+		if err == otherit.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	if diff := cmp.Diff([]int{0, 1, 2, 3, 4}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNextAdapterError(t *testing.T) {
+	it := NextAdapter(ints(5, 2))
+	it.PageInfo().MaxSize = 10
+	var got []int
+	var err error
+	for err == nil {
+		var v int
+		if v, err = it.Next(); err == nil {
+			got = append(got, v)
+		}
+	}
+	if err != errSeq {
+		t.Errorf("error = %v, want %v", err, errSeq)
+	}
+	if diff := cmp.Diff([]int{0, 1}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := it.Next(); err != errSeq {
+		t.Errorf("error after failure = %v, want %v", err, errSeq)
+	}
+}
+
+func TestNextAdapterStop(t *testing.T) {
+	stopped := false
+	seq := func(yield func(int, error) bool) {
+		defer func() { stopped = true }()
+		for i := 0; ; i++ {
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+	it := NextAdapter[int](seq)
+	if v, err := it.Next(); v != 0 || err != nil {
+		t.Fatalf("Next() = %v, %v, want 0, nil", v, err)
+	}
+	it.Stop()
+	if !stopped {
+		t.Error("sequence not stopped")
+	}
+	if _, err := it.Next(); err != otherit.Done {
+		t.Errorf("Next() after Stop error = %v, want %v", err, otherit.Done)
+	}
+}
+
+func TestNextAdapterPager(t *testing.T) {
+	// NextAdapter can back a Pager-based client iterator.
+	p := newTestPager(&fakeServer{n: 7, pageSize: 3})
+	it := NextAdapter(p.All(context.Background(), &listRequest{}))
+	pager := otherit.NewPager(it, 4, "")
+	var pages [][]int
+	for {
+		var page []int
+		token, err := pager.NextPage(&page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page)
+		if token == "" {
+			break
+		}
+	}
+	if diff := cmp.Diff([][]int{{0, 1, 2, 3}, {4, 5, 6}}, pages); diff != "" {
+		t.Errorf("pages mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+)
+
+// SourceError is the error yielded by Merge and MergeSorted when one of
+// their sources yields an error.
+//
+// This is for internal use only.
+type SourceError struct {
+	// Source is the index of the failed source in the arguments.
+	Source int
+	// Err is the error yielded by the source.
+	Err error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("iterator: source %d: %v", e.Source, e.Err)
+}
+
+// Unwrap returns the error yielded by the source.
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// mergeItem is an item read from source src.
+type mergeItem[T any] struct {
+	src int
+	v   T
+	err error
+}
+
+// Merge returns an iterator over the items of all seqs, in no particular
+// order. The seqs are read concurrently, with at most parallelism of them
+// being read at any time; the others are started as earlier ones are
+// exhausted. If parallelism is not positive, all seqs are read at once.
+//
+// The first error from a source is yielded as a *SourceError and ends the
+// iteration. If ctx is done before all seqs are exhausted, ctx.Err() is
+// yielded. Reading the seqs has stopped by the time the iteration is over.
+//
+// This is for internal use only.
+func Merge[T any](ctx context.Context, parallelism int, seqs ...iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if parallelism <= 0 {
+			parallelism = len(seqs)
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		items := make(chan mergeItem[T])
+		sem := make(chan struct{}, parallelism)
+		var (
+			mu       sync.Mutex
+			finished int
+			wg       sync.WaitGroup
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, seq := range seqs {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-sem }()
+					for v, err := range seq {
+						select {
+						case items <- mergeItem[T]{i, v, err}:
+						case <-ctx.Done():
+							return
+						}
+						if err != nil {
+							return
+						}
+					}
+					mu.Lock()
+					finished++
+					mu.Unlock()
+				}()
+			}
+		}()
+		go func() {
+			wg.Wait()
+			close(items)
+		}()
+		defer func() {
+			cancel()
+			for range items {
+			}
+		}()
+
+		for it := range items {
+			if it.err != nil {
+				yield(it.v, &SourceError{Source: it.src, Err: it.err})
+				return
+			}
+			if !yield(it.v, nil) {
+				return
+			}
+		}
+		if finished < len(seqs) {
+			var zero T
+			yield(zero, ctx.Err())
+		}
+	}
+}
+
+// mergeHeap orders the next item of each source for MergeSorted.
+type mergeHeap[T any] struct {
+	items []mergeItem[T]
+	cmp   func(a, b T) int
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.items) }
+
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	// Ties are broken by source index, which keeps the merge stable.
+	if c := h.cmp(h.items[i].v, h.items[j].v); c != 0 {
+		return c < 0
+	}
+	return h.items[i].src < h.items[j].src
+}
+
+func (h *mergeHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *mergeHeap[T]) Push(x any) { h.items = append(h.items, x.(mergeItem[T])) }
+
+func (h *mergeHeap[T]) Pop() any {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[:n-1]
+	return x
+}
+
+// MergeSorted returns an iterator over the items of all seqs, ordered by cmp,
+// which returns a negative number when a < b, a positive number when a > b and
+// zero when they are equal. Each seq must already be ordered by cmp. Equal
+// items are yielded in the order of their sources in the arguments.
+//
+// The first error from a source is yielded as a *SourceError and ends the
+// iteration.
+//
+// This is for internal use only.
+func MergeSorted[T any](cmp func(a, b T) int, seqs ...iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		pulls := make([]func() (T, error, bool), len(seqs))
+		for i, seq := range seqs {
+			next, stop := iter.Pull2(seq)
+			defer stop()
+			pulls[i] = next
+		}
+		h := &mergeHeap[T]{cmp: cmp}
+		// advance pushes the next item of source i, reporting false if the
+		// source yielded an error, which has then been yielded.
+		advance := func(i int) bool {
+			v, err, ok := pulls[i]()
+			if !ok {
+				return true
+			}
+			if err != nil {
+				yield(v, &SourceError{Source: i, Err: err})
+				return false
+			}
+			heap.Push(h, mergeItem[T]{src: i, v: v})
+			return true
+		}
+		for i := range pulls {
+			if !advance(i) {
+				return
+			}
+		}
+		for h.Len() > 0 {
+			it := heap.Pop(h).(mergeItem[T])
+			if !yield(it.v, nil) || !advance(it.src) {
+				return
+			}
+		}
+	}
+}
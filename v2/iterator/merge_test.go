@@ -0,0 +1,182 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+)
+
+// sliceSeq returns an iterator over items, yielding err after them if it is
+// not nil.
+func sliceSeq(items []int, err error) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for _, v := range items {
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(0, err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var active, peak atomic.Int32
+	track := func(seq iter.Seq2[int, error]) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			seq(yield)
+		}
+	}
+	var seqs []iter.Seq2[int, error]
+	var want []int
+	for i := range 5 {
+		items := []int{10 * i, 10*i + 1, 10*i + 2}
+		seqs = append(seqs, track(sliceSeq(items, nil)))
+		want = append(want, items...)
+	}
+	got, err := Collect(Merge(context.Background(), 2, seqs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	if diff := gocmp.Diff(want, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("read %d sources at once, want at most 2", p)
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	merged := Merge(context.Background(), 0,
+		sliceSeq([]int{1, 2}, nil),
+		sliceSeq([]int{3}, errSeq),
+	)
+	_, err := Collect(merged)
+	var srcErr *SourceError
+	if !errors.As(err, &srcErr) || srcErr.Source != 1 || !errors.Is(err, errSeq) {
+		t.Errorf("error = %v, want source 1 error wrapping %v", err, errSeq)
+	}
+}
+
+func TestMergeCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	endless := func(yield func(int, error) bool) {
+		for i := 0; yield(i, nil); i++ {
+		}
+	}
+	var gotErr error
+	for _, err := range Merge(ctx, 2, endless, endless) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		cancel()
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("error = %v, want %v", gotErr, context.Canceled)
+	}
+
+	// Breaking out stops the sources.
+	for range Merge(context.Background(), 2, endless, endless) {
+		break
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	got, err := Collect(MergeSorted(cmp.Compare[int],
+		sliceSeq([]int{1, 4, 7}, nil),
+		sliceSeq(nil, nil),
+		sliceSeq([]int{2, 4, 8, 9}, nil),
+		sliceSeq([]int{0, 3}, nil),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := gocmp.Diff([]int{0, 1, 2, 3, 4, 4, 7, 8, 9}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeSortedStable(t *testing.T) {
+	type item struct{ key, src int }
+	byKey := func(a, b item) int { return cmp.Compare(a.key, b.key) }
+	seq := func(src int, keys ...int) iter.Seq2[item, error] {
+		return func(yield func(item, error) bool) {
+			for _, k := range keys {
+				if !yield(item{k, src}, nil) {
+					return
+				}
+			}
+		}
+	}
+	got, err := Collect(MergeSorted(byKey, seq(0, 1, 2), seq(1, 1, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []item{{1, 0}, {1, 1}, {2, 0}, {2, 1}}
+	if diff := gocmp.Diff(want, got, gocmp.AllowUnexported(item{})); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMergeSortedError(t *testing.T) {
+	got, err := Collect(MergeSorted(cmp.Compare[int],
+		sliceSeq([]int{1, 5}, nil),
+		sliceSeq([]int{2, 3}, errSeq),
+	))
+	var srcErr *SourceError
+	if !errors.As(err, &srcErr) || srcErr.Source != 1 || !errors.Is(err, errSeq) {
+		t.Errorf("error = %v, want source 1 error wrapping %v", err, errSeq)
+	}
+	if diff := gocmp.Diff([]int{1, 2, 3}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
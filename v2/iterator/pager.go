@@ -0,0 +1,421 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"context"
+	"iter"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+	"github.com/googleapis/gax-go/v2/callctx"
+)
+
+// Page is a single page of results fetched by a Pager.
+type Page[Resp, Item any] struct {
+	// Response is the response the page was extracted from.
+	Response Resp
+	// Items are the items in the page.
+	Items []Item
+	// NextPageToken is the token of the following page, or empty if this is
+	// the last page.
+	NextPageToken string
+}
+
+// Pager fetches the pages of a paginated list method, following page tokens
+// until the last page. Each page is fetched through gax.Invoke, so it is
+// retried as configured by CallOptions.
+//
+// All function fields must be set, except CloneRequest, SetPageSize and
+// Size, which are needed only by the options documented as requiring them.
+//
+// This is for internal use only.
+type Pager[Req, Resp, Item any] struct {
+	// Fetch retrieves the page of results for req.
+	Fetch func(ctx context.Context, req Req, settings gax.CallSettings) (Resp, error)
+
+	// SetPageToken sets the page token of req.
+	SetPageToken func(req Req, token string)
+
+	// NextPageToken returns the token of the page following resp, or empty if
+	// resp is the last page.
+	NextPageToken func(resp Resp) string
+
+	// Items returns the items in resp.
+	Items func(resp Resp) []Item
+
+	// CallOptions are passed to gax.Invoke for every page.
+	CallOptions []gax.CallOption
+
+	// CloneRequest returns a copy of req that can be modified without
+	// affecting req. It must be set if Prefetch is set.
+	CloneRequest func(req Req) Req
+
+	// Prefetch is the number of pages to fetch ahead of the consumer in a
+	// background goroutine, which works on a copy of the request made by
+	// CloneRequest. If zero, pages are fetched only when needed.
+	Prefetch int
+
+	// PrefetchMaxItems bounds the number of items in pages that were
+	// prefetched but not yet consumed. No further page is fetched ahead until
+	// the consumer brings the count below this value. If zero, there is no
+	// limit.
+	PrefetchMaxItems int
+
+	// PrefetchMaxBytes is like PrefetchMaxItems, but bounds the total size of
+	// the prefetched responses as reported by Size. If zero, there is no
+	// limit.
+	PrefetchMaxBytes int
+
+	// Size returns the size of resp in bytes. It must be set if
+	// PrefetchMaxBytes or AdaptivePageSize.MaxBytes is set.
+	Size func(resp Resp) int
+
+	// SetPageSize sets the page size of req. It must be set if PageSize,
+	// MaxResults or AdaptivePageSize is set.
+	SetPageSize func(req Req, size int)
+
+	// PageSize is the page size to request. If zero, the server's default
+	// page size is used.
+	PageSize int
+
+	// MaxResults bounds the number of items fetched by an iteration. The page
+	// size of the final page is reduced to the number of items still wanted,
+	// and iteration stops once that many items have been fetched. If zero,
+	// there is no limit.
+	MaxResults int
+
+	// AdaptivePageSize, if set, adjusts the page size after every page,
+	// starting from PageSize.
+	AdaptivePageSize *AdaptivePageSize
+}
+
+// AdaptivePageSize adjusts the page size requested by a Pager based on the
+// latency and size of the pages fetched so far. The page size is halved after
+// a page that took longer than TargetLatency or was larger than MaxBytes, and
+// doubled after a full page that took less than half of TargetLatency.
+//
+// This is for internal use only.
+type AdaptivePageSize struct {
+	// Min is the smallest page size requested. If zero, 10 is used.
+	Min int
+
+	// Max is the largest page size requested. If zero, 1000 is used.
+	Max int
+
+	// TargetLatency is the time the fetch of a page should take. If zero, 1
+	// second is used.
+	TargetLatency time.Duration
+
+	// MaxBytes is the size that responses, as reported by Pager.Size, should
+	// not exceed. If zero, there is no limit.
+	MaxBytes int
+}
+
+func (a *AdaptivePageSize) min() int {
+	if a.Min == 0 {
+		return 10
+	}
+	return a.Min
+}
+
+func (a *AdaptivePageSize) max() int {
+	if a.Max == 0 {
+		return 1000
+	}
+	return a.Max
+}
+
+func (a *AdaptivePageSize) targetLatency() time.Duration {
+	if a.TargetLatency == 0 {
+		return time.Second
+	}
+	return a.TargetLatency
+}
+
+// clamp bounds size to [Min, Max].
+func (a *AdaptivePageSize) clamp(size int) int {
+	return min(max(size, a.min()), a.max())
+}
+
+// next returns the page size to request after a page of size was requested,
+// returning items items and bytes bytes in time d.
+func (a *AdaptivePageSize) next(size, items, bytes int, d time.Duration) int {
+	switch {
+	case d > a.targetLatency() || (a.MaxBytes > 0 && bytes > a.MaxBytes):
+		size /= 2
+	case d < a.targetLatency()/2 && items >= size:
+		size *= 2
+	}
+	return a.clamp(size)
+}
+
+// pageSizer tracks the page size to request during an iteration.
+type pageSizer struct {
+	// size is the page size to request, or zero for the server default.
+	size int
+	// remaining is the number of items still wanted under MaxResults, or
+	// negative if there is no limit.
+	remaining int
+	adaptive  *AdaptivePageSize
+}
+
+func (p *Pager[Req, Resp, Item]) newPageSizer() *pageSizer {
+	ps := &pageSizer{size: p.PageSize, remaining: -1, adaptive: p.AdaptivePageSize}
+	if p.MaxResults > 0 {
+		ps.remaining = p.MaxResults
+	}
+	if ps.adaptive != nil {
+		ps.size = ps.adaptive.clamp(ps.size)
+	}
+	return ps
+}
+
+// pageSize returns the page size to request for the next page, or zero for
+// the server default.
+func (ps *pageSizer) pageSize() int {
+	if ps.remaining >= 0 && (ps.size <= 0 || ps.size > ps.remaining) {
+		return ps.remaining
+	}
+	return ps.size
+}
+
+// done reports whether MaxResults items have been fetched.
+func (ps *pageSizer) done() bool {
+	return ps.remaining == 0
+}
+
+// Pages returns an iterator over the pages of results for req, starting from
+// the page token already set on req. Once a page has been yielded and
+// iteration continues, req is updated with the token of the following page,
+// so that it always records the position of the consumer and may be used to
+// resume iteration. Iteration stops after the first error.
+//
+// If MaxResults is set, the page that reaches it is the last one yielded, and
+// any items beyond MaxResults are removed from it.
+//
+// If Prefetch is set, pages are fetched ahead in a background goroutine,
+// which is stopped before iteration returns. The goroutine never modifies
+// req, so req may be read while iterating.
+func (p *Pager[Req, Resp, Item]) Pages(ctx context.Context, req Req) iter.Seq2[Page[Resp, Item], error] {
+	return func(yield func(Page[Resp, Item], error) bool) {
+		if p.Prefetch > 0 {
+			p.prefetch(ctx, req, yield)
+			return
+		}
+		ps := p.newPageSizer()
+		for index := 0; ; index++ {
+			page, err := p.fetch(ctx, req, index, ps)
+			if err != nil {
+				yield(Page[Resp, Item]{}, err)
+				return
+			}
+			if !yield(page, nil) || page.NextPageToken == "" || ps.done() {
+				return
+			}
+			p.SetPageToken(req, page.NextPageToken)
+		}
+	}
+}
+
+// All returns an iterator over the items of every page of results for req.
+// See Pages.
+func (p *Pager[Req, Resp, Item]) All(ctx context.Context, req Req) iter.Seq2[Item, error] {
+	return func(yield func(Item, error) bool) {
+		for page, err := range p.Pages(ctx, req) {
+			if err != nil {
+				var zero Item
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// fetch retrieves the page at index, counted from the first page fetched by
+// this iteration, for req, with the page size given by ps.
+func (p *Pager[Req, Resp, Item]) fetch(ctx context.Context, req Req, index int, ps *pageSizer) (page Page[Resp, Item], err error) {
+	// Feature gate: GOOGLE_SDK_GO_EXPERIMENTAL_TRACING=true
+	if gax.IsFeatureEnabled("TRACING") {
+		ctx = withPageIndex(ctx, index)
+	}
+	if gax.IsFeatureEnabled("METRICS") {
+		start := time.Now()
+		defer func() {
+			gax.RecordPageMetrics(ctx, len(page.Items), time.Since(start), err, p.CallOptions...)
+		}()
+	}
+	size := ps.pageSize()
+	if size > 0 {
+		p.SetPageSize(req, size)
+	}
+	start := time.Now()
+	resp, err := gax.InvokeValue(ctx, func(ctx context.Context, settings gax.CallSettings) (Resp, error) {
+		return p.Fetch(ctx, req, settings)
+	}, p.CallOptions...)
+	if err != nil {
+		return Page[Resp, Item]{}, err
+	}
+	d := time.Since(start)
+	page = Page[Resp, Item]{
+		Response:      resp,
+		Items:         p.Items(resp),
+		NextPageToken: p.NextPageToken(resp),
+	}
+	if ps.remaining >= 0 {
+		// The server may return more items than requested.
+		if len(page.Items) > ps.remaining {
+			page.Items = page.Items[:ps.remaining]
+		}
+		ps.remaining -= len(page.Items)
+	}
+	if a := ps.adaptive; a != nil {
+		var bytes int
+		if a.MaxBytes > 0 {
+			bytes = p.Size(resp)
+		}
+		ps.size = a.next(ps.size, len(page.Items), bytes, d)
+	}
+	return page, nil
+}
+
+// withPageIndex returns a new context with the page index appended to the
+// telemetry context, alongside the resend count added by gax.Invoke. The first
+// page fetched has index 0.
+func withPageIndex(ctx context.Context, index int) context.Context {
+	return callctx.WithTelemetryContext(ctx, "page_index", strconv.Itoa(index))
+}
+
+// pageResult is a page fetched in the background by prefetch.
+type pageResult[Resp, Item any] struct {
+	page Page[Resp, Item]
+	err  error
+	size int
+	// last reports whether no page follows this one.
+	last bool
+}
+
+// prefetch yields the pages for req while a background goroutine fetches up
+// to p.Prefetch pages ahead.
+func (p *Pager[Req, Resp, Item]) prefetch(ctx context.Context, req Req, yield func(Page[Resp, Item], error) bool) {
+	fetchCtx, cancel := context.WithCancel(ctx)
+	// The goroutine holding a page it cannot yet send counts as one page
+	// ahead, so the channel buffers one less.
+	results := make(chan pageResult[Resp, Item], p.Prefetch-1)
+	var (
+		mu    sync.Mutex
+		items int
+		bytes int
+	)
+	freed := make(chan struct{}, 1)
+	withinBudget := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if items == 0 && bytes == 0 {
+			return true
+		}
+		return (p.PrefetchMaxItems <= 0 || items < p.PrefetchMaxItems) &&
+			(p.PrefetchMaxBytes <= 0 || bytes < p.PrefetchMaxBytes)
+	}
+
+	ps := p.newPageSizer()
+	// The goroutine works on a copy, leaving req to the consumer.
+	ahead := p.CloneRequest(req)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(results)
+		for index := 0; ; index++ {
+			for !withinBudget() {
+				select {
+				case <-freed:
+				case <-fetchCtx.Done():
+					return
+				}
+			}
+			page, err := p.fetch(fetchCtx, ahead, index, ps)
+			r := pageResult[Resp, Item]{
+				page: page,
+				err:  err,
+				last: page.NextPageToken == "" || ps.done(),
+			}
+			if err == nil && p.PrefetchMaxBytes > 0 {
+				r.size = p.Size(page.Response)
+			}
+			mu.Lock()
+			items += len(page.Items)
+			bytes += r.size
+			mu.Unlock()
+			select {
+			case results <- r:
+			case <-fetchCtx.Done():
+				return
+			}
+			if err != nil || r.last {
+				return
+			}
+			p.SetPageToken(ahead, page.NextPageToken)
+		}
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	for r := range results {
+		mu.Lock()
+		items -= len(r.page.Items)
+		bytes -= r.size
+		mu.Unlock()
+		select {
+		case freed <- struct{}{}:
+		default:
+		}
+		if r.err != nil {
+			yield(Page[Resp, Item]{}, r.err)
+			return
+		}
+		if !yield(r.page, nil) || r.last {
+			return
+		}
+		p.SetPageToken(req, r.page.NextPageToken)
+	}
+	// The goroutine stopped before the last page, which only happens when
+	// ctx is done.
+	yield(Page[Resp, Item]{}, ctx.Err())
+}
@@ -0,0 +1,463 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/googleapis/gax-go/v2/callctx"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type listRequest struct {
+	pageToken string
+	pageSize  int
+}
+
+type listResponse struct {
+	items         []int
+	nextPageToken string
+}
+
+// fakeServer serves the integers [0, n) in pages of size pageSize, or of the
+// requested size if set.
+type fakeServer struct {
+	n        int
+	pageSize int
+	// failures is the number of Unavailable errors returned before each
+	// page succeeds.
+	failures int
+	attempts int
+	requests []listRequest
+}
+
+func (s *fakeServer) list(_ context.Context, req *listRequest, _ gax.CallSettings) (*listResponse, error) {
+	s.attempts++
+	if s.failures > 0 && s.attempts%(s.failures+1) != 0 {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	s.requests = append(s.requests, *req)
+	start := 0
+	if req.pageToken != "" {
+		start, _ = strconv.Atoi(req.pageToken)
+	}
+	size := s.pageSize
+	if req.pageSize > 0 {
+		size = req.pageSize
+	}
+	end := min(start+size, s.n)
+	resp := &listResponse{}
+	for i := start; i < end; i++ {
+		resp.items = append(resp.items, i)
+	}
+	if end < s.n {
+		resp.nextPageToken = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
+func newTestPager(s *fakeServer, opts ...gax.CallOption) *Pager[*listRequest, *listResponse, int] {
+	return &Pager[*listRequest, *listResponse, int]{
+		Fetch:         s.list,
+		SetPageToken:  func(req *listRequest, token string) { req.pageToken = token },
+		NextPageToken: func(resp *listResponse) string { return resp.nextPageToken },
+		Items:         func(resp *listResponse) []int { return resp.items },
+		CloneRequest: func(req *listRequest) *listRequest {
+			c := *req
+			return &c
+		},
+		CallOptions: opts,
+	}
+}
+
+func TestPagerAll(t *testing.T) {
+	s := &fakeServer{n: 7, pageSize: 3}
+	var got []int
+	for v, err := range newTestPager(s).All(context.Background(), &listRequest{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	if diff := cmp.Diff([]int{0, 1, 2, 3, 4, 5, 6}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPagerPages(t *testing.T) {
+	s := &fakeServer{n: 5, pageSize: 2}
+	var tokens []string
+	var sizes []int
+	for page, err := range newTestPager(s).Pages(context.Background(), &listRequest{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, page.NextPageToken)
+		sizes = append(sizes, len(page.Items))
+	}
+	if diff := cmp.Diff([]string{"2", "4", ""}, tokens); diff != "" {
+		t.Errorf("tokens mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{2, 2, 1}, sizes); diff != "" {
+		t.Errorf("page sizes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPagerRetry(t *testing.T) {
+	s := &fakeServer{n: 4, pageSize: 2, failures: 1}
+	p := newTestPager(s,
+		gax.WithSleep(func(context.Context, time.Duration) error { return nil }),
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{})
+		}))
+	n := 0
+	for _, err := range p.All(context.Background(), &listRequest{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 4 {
+		t.Errorf("got %d items, want 4", n)
+	}
+	if s.attempts != 4 {
+		t.Errorf("got %d attempts, want 4", s.attempts)
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	apiErr := errors.New("foo error")
+	p := newTestPager(&fakeServer{})
+	p.Fetch = func(context.Context, *listRequest, gax.CallSettings) (*listResponse, error) {
+		return nil, apiErr
+	}
+	var errs []error
+	for _, err := range p.All(context.Background(), &listRequest{}) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] != apiErr {
+		t.Errorf("got errors %v, want [%v]", errs, apiErr)
+	}
+}
+
+func TestPagerBreak(t *testing.T) {
+	s := &fakeServer{n: 10, pageSize: 2}
+	for v := range newTestPager(s).All(context.Background(), &listRequest{}) {
+		if v == 2 {
+			break
+		}
+	}
+	if len(s.requests) != 2 {
+		t.Errorf("fetched %d pages, want 2", len(s.requests))
+	}
+}
+
+// countingPager returns a pager whose fetches are counted in fetched.
+func countingPager(s *fakeServer, fetched *atomic.Int32) *Pager[*listRequest, *listResponse, int] {
+	p := newTestPager(s)
+	p.Fetch = func(ctx context.Context, req *listRequest, settings gax.CallSettings) (*listResponse, error) {
+		defer fetched.Add(1)
+		return s.list(ctx, req, settings)
+	}
+	return p
+}
+
+// waitFetched waits until fetched reaches want, then checks that it stays
+// there.
+func waitFetched(t *testing.T, fetched *atomic.Int32, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for fetched.Load() < want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := fetched.Load(); got != want {
+		t.Errorf("fetched %d pages, want %d", got, want)
+	}
+}
+
+func TestPagerPrefetch(t *testing.T) {
+	for _, tst := range []struct {
+		name     string
+		pageSize int
+		prefetch int
+		maxItems int
+		maxBytes int
+		// want is the number of pages fetched while the consumer holds
+		// the first page.
+		want int32
+	}{
+		{name: "pages", pageSize: 1, prefetch: 2, want: 3},
+		{name: "max_items", pageSize: 2, prefetch: 5, maxItems: 3, want: 3},
+		{name: "max_bytes", pageSize: 2, prefetch: 5, maxBytes: 24, want: 3},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			var fetched atomic.Int32
+			p := countingPager(&fakeServer{n: 20, pageSize: tst.pageSize}, &fetched)
+			p.Prefetch = tst.prefetch
+			p.PrefetchMaxItems = tst.maxItems
+			p.PrefetchMaxBytes = tst.maxBytes
+			p.Size = func(resp *listResponse) int { return 8 * len(resp.items) }
+
+			var got []int
+			for page, err := range p.Pages(context.Background(), &listRequest{}) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) == 0 {
+					waitFetched(t, &fetched, tst.want)
+				}
+				got = append(got, page.Items...)
+			}
+			if len(got) != 20 {
+				t.Errorf("got %d items, want 20", len(got))
+			}
+		})
+	}
+}
+
+func TestPagerPrefetchBreak(t *testing.T) {
+	var fetched atomic.Int32
+	req := &listRequest{}
+	p := countingPager(&fakeServer{n: 20, pageSize: 1}, &fetched)
+	p.Prefetch = 3
+	for range p.All(context.Background(), req) {
+		waitFetched(t, &fetched, 4)
+		break
+	}
+	// The background fetches have stopped, so the request is no longer
+	// being updated.
+	token := req.pageToken
+	time.Sleep(20 * time.Millisecond)
+	if req.pageToken != token || fetched.Load() != 4 {
+		t.Errorf("pages fetched after iteration returned")
+	}
+}
+
+func TestPagerPrefetchRequestToken(t *testing.T) {
+	// Run with -race: the consumer reads req while pages are fetched ahead.
+	var fetched atomic.Int32
+	req := &listRequest{}
+	p := countingPager(&fakeServer{n: 6, pageSize: 1}, &fetched)
+	p.Prefetch = 3
+	var tokens []string
+	for page, err := range p.Pages(context.Background(), req) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		// req records the page being consumed, not the pages fetched ahead.
+		tokens = append(tokens, req.pageToken)
+		if page.Items[0] == 0 {
+			waitFetched(t, &fetched, 4)
+		}
+	}
+	if diff := cmp.Diff([]string{"", "1", "2", "3", "4", "5"}, tokens); diff != "" {
+		t.Errorf("tokens mismatch (-want +got):\n%s", diff)
+	}
+	if req.pageToken != "5" {
+		t.Errorf("got token %q after iteration, want 5", req.pageToken)
+	}
+}
+
+func TestPagerPrefetchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newTestPager(&fakeServer{n: 20, pageSize: 1})
+	p.Prefetch = 2
+	var errs []error
+	for _, err := range p.Pages(ctx, &listRequest{}) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("got errors %v, want [%v]", errs, context.Canceled)
+	}
+}
+
+func TestPagerTelemetry(t *testing.T) {
+	t.Setenv("GOOGLE_SDK_GO_EXPERIMENTAL_METRICS", "true")
+	t.Setenv("GOOGLE_SDK_GO_EXPERIMENTAL_TRACING", "true")
+	gax.TestOnlyResetIsFeatureEnabled()
+	defer gax.TestOnlyResetIsFeatureEnabled()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	cm := gax.NewClientMetrics(gax.WithMeterProvider(provider))
+
+	s := &fakeServer{n: 5, pageSize: 2, failures: 1}
+	p := newTestPager(s,
+		gax.WithClientMetrics(cm),
+		gax.WithSleep(func(context.Context, time.Duration) error { return nil }),
+		gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{})
+		}))
+	var annotations []string
+	p.Fetch = func(ctx context.Context, req *listRequest, settings gax.CallSettings) (*listResponse, error) {
+		page, _ := callctx.TelemetryFromContext(ctx, "page_index")
+		resend, _ := callctx.TelemetryFromContext(ctx, "resend_count")
+		annotations = append(annotations, page+"/"+resend)
+		return s.list(ctx, req, settings)
+	}
+	ctx := callctx.WithTelemetryContext(context.Background(), "rpc_method", "my.service.List")
+	if _, err := Collect(p.All(ctx, &listRequest{})); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"0/0", "0/1", "1/0", "1/1", "2/0", "2/1"}
+	if diff := cmp.Diff(want, annotations); diff != "" {
+		t.Errorf("page_index/resend_count mismatch (-want +got):\n%s", diff)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	var pages int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "gcp.client.pagination.pages" {
+				for _, dp := range sum.DataPoints {
+					pages += dp.Value
+				}
+			}
+		}
+	}
+	if pages != 3 {
+		t.Errorf("recorded %d pages, want 3", pages)
+	}
+}
+
+func TestPagerMaxResults(t *testing.T) {
+	for _, tst := range []struct {
+		name       string
+		pageSize   int
+		maxResults int
+		prefetch   int
+		// ignoreSize makes the server ignore the requested page size.
+		ignoreSize bool
+		wantSizes  []int
+		wantItems  int
+	}{
+		{name: "trim_final_page", pageSize: 2, maxResults: 5, wantSizes: []int{2, 2, 1}, wantItems: 5},
+		{name: "server_default", maxResults: 5, wantSizes: []int{5}, wantItems: 5},
+		{name: "fewer_items", pageSize: 4, maxResults: 50, wantSizes: []int{4, 4, 4}, wantItems: 10},
+		{name: "ignored_size", maxResults: 4, ignoreSize: true, wantSizes: []int{0, 0}, wantItems: 4},
+		{name: "prefetch", pageSize: 2, maxResults: 4, prefetch: 3, wantSizes: []int{2, 2}, wantItems: 4},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			s := &fakeServer{n: 10, pageSize: 3}
+			p := newTestPager(s)
+			p.PageSize = tst.pageSize
+			p.MaxResults = tst.maxResults
+			p.Prefetch = tst.prefetch
+			p.SetPageSize = func(req *listRequest, size int) {
+				if !tst.ignoreSize {
+					req.pageSize = size
+				}
+			}
+			items, err := Collect(p.All(context.Background(), &listRequest{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(items) != tst.wantItems {
+				t.Errorf("got %d items, want %d", len(items), tst.wantItems)
+			}
+			var sizes []int
+			for _, req := range s.requests {
+				sizes = append(sizes, req.pageSize)
+			}
+			if diff := cmp.Diff(tst.wantSizes, sizes); diff != "" {
+				t.Errorf("requested page sizes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAdaptivePageSize(t *testing.T) {
+	a := &AdaptivePageSize{Min: 5, Max: 100, TargetLatency: time.Second, MaxBytes: 1000}
+	for _, tst := range []struct {
+		name  string
+		size  int
+		items int
+		bytes int
+		d     time.Duration
+		want  int
+	}{
+		{"fast_full_page", 20, 20, 100, 100 * time.Millisecond, 40},
+		{"fast_short_page", 20, 12, 100, 100 * time.Millisecond, 20},
+		{"on_target", 20, 20, 100, 700 * time.Millisecond, 20},
+		{"slow", 20, 20, 100, 2 * time.Second, 10},
+		{"large", 20, 20, 5000, 100 * time.Millisecond, 10},
+		{"at_max", 80, 80, 100, 100 * time.Millisecond, 100},
+		{"at_min", 6, 6, 100, 2 * time.Second, 5},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			if got := a.next(tst.size, tst.items, tst.bytes, tst.d); got != tst.want {
+				t.Errorf("next() = %d, want %d", got, tst.want)
+			}
+		})
+	}
+
+	var zero AdaptivePageSize
+	if zero.min() != 10 || zero.max() != 1000 || zero.targetLatency() != time.Second {
+		t.Errorf("defaults = %d, %d, %v, want 10, 1000, 1s", zero.min(), zero.max(), zero.targetLatency())
+	}
+}
+
+func TestPagerAdaptivePageSize(t *testing.T) {
+	s := &fakeServer{n: 100, pageSize: 10}
+	p := newTestPager(s)
+	p.SetPageSize = func(req *listRequest, size int) { req.pageSize = size }
+	p.MaxResults = 50
+	p.AdaptivePageSize = &AdaptivePageSize{Min: 4, Max: 16, TargetLatency: time.Hour}
+	if _, err := Collect(p.All(context.Background(), &listRequest{})); err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int
+	for _, req := range s.requests {
+		sizes = append(sizes, req.pageSize)
+	}
+	if diff := cmp.Diff([]int{4, 8, 16, 16, 6}, sizes); diff != "" {
+		t.Errorf("requested page sizes mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Collect returns the items of seq up to its first error, along with that
+// error.
+//
+// This is for internal use only.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for v, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// Take returns an iterator over the first n items of seq. An error from seq
+// is yielded in place of an item and ends the iteration.
+//
+// This is for internal use only.
+func Take[T any](seq iter.Seq2[T, error], n int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v, err := range seq {
+			if !yield(v, err) || err != nil {
+				return
+			}
+			if i++; i == n {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns an iterator over the items of seq for which keep returns
+// true. An error from seq is always yielded and ends the iteration.
+//
+// This is for internal use only.
+func Filter[T any](seq iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for v, err := range seq {
+			if err != nil {
+				yield(v, err)
+				return
+			}
+			if keep(v) && !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns an iterator over the results of fn applied to the items of seq.
+// An error from seq or fn is yielded and ends the iteration.
+//
+// This is for internal use only.
+func Map[T, U any](seq iter.Seq2[T, error], fn func(T) (U, error)) iter.Seq2[U, error] {
+	return func(yield func(U, error) bool) {
+		for v, err := range seq {
+			var u U
+			if err == nil {
+				u, err = fn(v)
+			}
+			if !yield(u, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Batch returns an iterator over the items of seq grouped in slices of n
+// items, except possibly the last one. An error from seq is yielded after
+// any incomplete batch and ends the iteration.
+//
+// This is for internal use only.
+func Batch[T any](seq iter.Seq2[T, error], n int) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		var batch []T
+		for v, err := range seq {
+			if err != nil {
+				if len(batch) > 0 && !yield(batch, nil) {
+					return
+				}
+				yield(nil, err)
+				return
+			}
+			batch = append(batch, v)
+			if len(batch) >= n {
+				if !yield(batch, nil) {
+					return
+				}
+				batch = nil
+			}
+		}
+		if len(batch) > 0 {
+			yield(batch, nil)
+		}
+	}
+}
+
+// FirstError splits seq into an iterator over its items, which ends at the
+// first error, and a function returning that error once the iteration is
+// over.
+//
+// This is for internal use only.
+func FirstError[T any](seq iter.Seq2[T, error]) (iter.Seq[T], func() error) {
+	var firstErr error
+	items := func(yield func(T) bool) {
+		for v, err := range seq {
+			if err != nil {
+				firstErr = err
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+	return items, func() error { return firstErr }
+}
+
+// mapResult is the outcome of a single ParallelMap call.
+type mapResult[U any] struct {
+	v   U
+	err error
+}
+
+// ParallelMap is like Map, but calls fn on up to workers items concurrently.
+// Results are yielded in the order of seq. The context passed to fn is
+// canceled when the iteration ends early, and all calls to fn have returned
+// by the time the iteration is over. If ctx is done before seq is exhausted,
+// ctx.Err() is yielded.
+//
+// This is for internal use only.
+func ParallelMap[T, U any](ctx context.Context, seq iter.Seq2[T, error], workers int, fn func(context.Context, T) (U, error)) iter.Seq2[U, error] {
+	return func(yield func(U, error) bool) {
+		workers := max(workers, 1)
+		ctx, cancel := context.WithCancel(ctx)
+		// pending holds the result channels of the started calls, in the
+		// order of seq.
+		pending := make(chan chan mapResult[U], workers)
+		sem := make(chan struct{}, workers)
+		complete := false
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(pending)
+			for v, err := range seq {
+				res := make(chan mapResult[U], 1)
+				if err != nil {
+					res <- mapResult[U]{err: err}
+					select {
+					case pending <- res:
+						complete = true
+					case <-ctx.Done():
+					}
+					return
+				}
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case pending <- res:
+				case <-ctx.Done():
+					<-sem
+					return
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					u, err := fn(ctx, v)
+					<-sem
+					res <- mapResult[U]{u, err}
+				}()
+			}
+			complete = true
+		}()
+		defer wg.Wait()
+		defer cancel()
+
+		for res := range pending {
+			r := <-res
+			if !yield(r.v, r.err) || r.err != nil {
+				return
+			}
+		}
+		if !complete {
+			var zero U
+			yield(zero, ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+//go:build go1.23
+
+package iterator
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var errSeq = errors.New("seq error")
+
+// ints returns an iterator over the integers [0, n), yielding errSeq
+// instead of the item at index failAt if it is not negative.
+func ints(n, failAt int) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for i := range n {
+			if i == failAt {
+				yield(0, errSeq)
+				return
+			}
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	got, err := Collect(ints(4, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{0, 1, 2, 3}, got); diff != "" {
+		t.Errorf("Collect mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = Collect(ints(4, 2))
+	if err != errSeq {
+		t.Errorf("Collect error = %v, want %v", err, errSeq)
+	}
+	if diff := cmp.Diff([]int{0, 1}, got); diff != "" {
+		t.Errorf("Collect mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+	double := func(v int) (int, error) { return 2 * v, nil }
+	for _, tst := range []struct {
+		name    string
+		seq     iter.Seq2[int, error]
+		want    []int
+		wantErr error
+	}{
+		{"take", Take(ints(10, -1), 3), []int{0, 1, 2}, nil},
+		{"take_zero", Take(ints(10, -1), 0), nil, nil},
+		{"take_error", Take(ints(10, 1), 3), []int{0}, errSeq},
+		{"filter", Filter(ints(6, -1), even), []int{0, 2, 4}, nil},
+		{"filter_error", Filter(ints(6, 3), even), []int{0, 2}, errSeq},
+		{"map", Map(ints(3, -1), double), []int{0, 2, 4}, nil},
+		{"map_error", Map(ints(3, 2), double), []int{0, 2}, errSeq},
+		{"map_fn_error", Map(ints(3, -1), func(v int) (int, error) {
+			if v == 1 {
+				return 0, errSeq
+			}
+			return v, nil
+		}), []int{0}, errSeq},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := Collect(tst.seq)
+			if err != tst.wantErr {
+				t.Errorf("error = %v, want %v", err, tst.wantErr)
+			}
+			if diff := cmp.Diff(tst.want, got); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	got, err := Collect(Batch(ints(5, -1), 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]int{{0, 1}, {2, 3}, {4}}, got); diff != "" {
+		t.Errorf("Batch mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = Collect(Batch(ints(5, 3), 2))
+	if err != errSeq {
+		t.Errorf("Batch error = %v, want %v", err, errSeq)
+	}
+	if diff := cmp.Diff([][]int{{0, 1}, {2}}, got); diff != "" {
+		t.Errorf("Batch mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFirstError(t *testing.T) {
+	items, errf := FirstError(ints(5, 3))
+	var got []int
+	for v := range items {
+		got = append(got, v)
+	}
+	if diff := cmp.Diff([]int{0, 1, 2}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+	if err := errf(); err != errSeq {
+		t.Errorf("error = %v, want %v", err, errSeq)
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	var running, peak atomic.Int32
+	fn := func(_ context.Context, v int) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		return v * v, nil
+	}
+	got, err := Collect(ParallelMap(context.Background(), ints(20, -1), 3, fn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []int
+	for i := range 20 {
+		want = append(want, i*i)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParallelMap mismatch (-want +got):\n%s", diff)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("got %d concurrent calls, want at most 3", p)
+	}
+}
+
+func TestParallelMapErrors(t *testing.T) {
+	square := func(_ context.Context, v int) (int, error) { return v * v, nil }
+	got, err := Collect(ParallelMap(context.Background(), ints(10, 4), 3, square))
+	if err != errSeq {
+		t.Errorf("error = %v, want %v", err, errSeq)
+	}
+	if diff := cmp.Diff([]int{0, 1, 4, 9}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+
+	failing := func(_ context.Context, v int) (int, error) {
+		if v == 2 {
+			return 0, errSeq
+		}
+		return v, nil
+	}
+	got, err = Collect(ParallelMap(context.Background(), ints(10, -1), 3, failing))
+	if err != errSeq {
+		t.Errorf("error = %v, want %v", err, errSeq)
+	}
+	if diff := cmp.Diff([]int{0, 1}, got); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParallelMapCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var canceled atomic.Int32
+	fn := func(ctx context.Context, v int) (int, error) {
+		if v == 0 {
+			return v, nil
+		}
+		<-ctx.Done()
+		canceled.Add(1)
+		return 0, ctx.Err()
+	}
+	for v, err := range ParallelMap(ctx, ints(100, -1), 4, fn) {
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want %v", err, context.Canceled)
+			}
+			break
+		}
+		if v == 0 {
+			cancel()
+		}
+	}
+	// Break ends the iteration, after which no call is still running.
+	for range ParallelMap(context.Background(), ints(100, -1), 4, fn) {
+		break
+	}
+	if canceled.Load() == 0 {
+		t.Error("no call observed the cancellation")
+	}
+}
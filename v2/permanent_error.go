@@ -0,0 +1,108 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+var (
+	// permanentErrorMu guards permanentErrorClassifiers.
+	permanentErrorMu          sync.RWMutex
+	permanentErrorClassifiers = []func(error) bool{
+		isCertificateError,
+		isDNSNotFoundError,
+	}
+)
+
+// RegisterPermanentErrorClassifier adds fn to the set of classifiers that
+// Invoke consults before retrying a failed APICall. If any classifier reports
+// true for an error, Invoke returns that error immediately, regardless of the
+// configured Retryer.
+//
+// Classifiers should only match errors that cannot succeed on retry, such as
+// misconfiguration of the local environment. By default, certificate
+// verification errors and DNS "no such host" errors are considered permanent.
+// RegisterPermanentErrorClassifier is safe for concurrent use, but is
+// typically called during program initialization.
+func RegisterPermanentErrorClassifier(fn func(err error) bool) {
+	permanentErrorMu.Lock()
+	defer permanentErrorMu.Unlock()
+	permanentErrorClassifiers = append(permanentErrorClassifiers, fn)
+}
+
+// IsPermanentError reports whether any registered classifier considers err to
+// be permanent. See RegisterPermanentErrorClassifier.
+func IsPermanentError(err error) bool {
+	if err == nil {
+		return false
+	}
+	permanentErrorMu.RLock()
+	defer permanentErrorMu.RUnlock()
+	for _, fn := range permanentErrorClassifiers {
+		if fn(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCertificateError reports whether err is caused by a failure to verify the
+// server's certificate, e.g. if ca-certificates are not installed, the
+// certificate has expired, or it does not match the requested host.
+func isCertificateError(err error) bool {
+	var verr *tls.CertificateVerificationError
+	var uaerr x509.UnknownAuthorityError
+	var herr x509.HostnameError
+	var ierr x509.CertificateInvalidError
+	if errors.As(err, &verr) || errors.As(err, &uaerr) || errors.As(err, &herr) || errors.As(err, &ierr) {
+		return true
+	}
+	// gRPC reports transport failures as a status with the original error
+	// flattened into the message, so the error types above are lost. We
+	// should only make very few, targeted exceptions here: many (other)
+	// status=Unavailable should be retried, such as if there's a network
+	// hiccup, or the internet goes out for a minute. This is also why here we
+	// are doing string parsing instead of simply making Unavailable a
+	// non-retried code elsewhere.
+	return strings.Contains(err.Error(), "x509: certificate signed by unknown authority")
+}
+
+// isDNSNotFoundError reports whether err is caused by a DNS lookup for a host
+// that does not exist.
+func isDNSNotFoundError(err error) bool {
+	var derr *net.DNSError
+	return errors.As(err, &derr) && derr.IsNotFound
+}
@@ -0,0 +1,98 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsPermanentError(t *testing.T) {
+	for _, tst := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"generic", errors.New("foo error"), false},
+		{"unavailable", status.Error(codes.Unavailable, "connection reset"), false},
+		{"unknown_authority", x509.UnknownAuthorityError{}, true},
+		{"unknown_authority_wrapped", fmt.Errorf("dial: %w", x509.UnknownAuthorityError{}), true},
+		{"unknown_authority_status", status.Error(codes.Unavailable, "x509: certificate signed by unknown authority"), true},
+		{"hostname", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "example.com"}, true},
+		{"expired", x509.CertificateInvalidError{Reason: x509.Expired}, true},
+		{"tls_verification", &tls.CertificateVerificationError{Err: errors.New("bad cert")}, true},
+		{"dns_not_found", &net.DNSError{Name: "nope.invalid", IsNotFound: true}, true},
+		{"dns_timeout", &net.DNSError{Name: "example.com", IsTimeout: true}, false},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			if got := IsPermanentError(tst.err); got != tst.want {
+				t.Errorf("IsPermanentError(%v) = %t, want %t", tst.err, got, tst.want)
+			}
+		})
+	}
+}
+
+func TestRegisterPermanentErrorClassifier(t *testing.T) {
+	saved := permanentErrorClassifiers
+	defer func() { permanentErrorClassifiers = saved }()
+
+	errPermanent := errors.New("permanent")
+	RegisterPermanentErrorClassifier(func(err error) bool {
+		return errors.Is(err, errPermanent)
+	})
+
+	calls := 0
+	apiCall := func(context.Context, CallSettings) error {
+		calls++
+		return fmt.Errorf("wrapped: %w", errPermanent)
+	}
+	var settings CallSettings
+	WithRetry(func() Retryer { return boolRetryer(true) }).Resolve(&settings)
+	var sp recordSleeper
+	err := invoke(context.Background(), apiCall, settings, sp.sleep)
+	if !errors.Is(err, errPermanent) {
+		t.Errorf("found error %v, want %v", err, errPermanent)
+	}
+	if calls != 1 {
+		t.Errorf("called %d times, want 1", calls)
+	}
+	if sp != 0 {
+		t.Errorf("slept %d times, should not have slept for a permanent error", int(sp))
+	}
+}
@@ -0,0 +1,351 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ResponseCache caches the responses of idempotent read methods, keyed by
+// method and serialized request. Entries are evicted in least recently used
+// order once MaxEntries is exceeded. See WithResponseCache.
+//
+// For REST transports, ResponseCache honors the Cache-Control and ETag
+// response headers reported through HTTPCacheData.
+//
+// A ResponseCache must not be copied after first use. It is safe for
+// concurrent use by multiple goroutines.
+type ResponseCache struct {
+	// TTL is how long a cached response is served without contacting the
+	// server, defaults to 1 minute. A Cache-Control max-age directive takes
+	// precedence.
+	TTL time.Duration
+
+	// MaxEntries is the maximum number of cached responses, defaults to 1000.
+	MaxEntries int
+
+	// StaleWhileRefreshing is how long past its expiry a cached response may
+	// still be served to callers while another caller refreshes it. The
+	// first caller to find the response expired refreshes it synchronously,
+	// as on a miss, since the APICall stores the response in that caller's
+	// variable; the response is never revalidated in the background. Zero
+	// disables serving stale responses.
+	StaleWhileRefreshing time.Duration
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	// now is time.Now, overridable for testing.
+	now func() time.Time
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	key     string
+	val     any
+	etag    string
+	expires time.Time
+	// refreshing reports whether a caller is revalidating the entry.
+	refreshing bool
+}
+
+func (c *ResponseCache) ttl() time.Duration {
+	if c.TTL <= 0 {
+		return time.Minute
+	}
+	return c.TTL
+}
+
+func (c *ResponseCache) maxEntries() int {
+	if c.MaxEntries <= 0 {
+		return 1000
+	}
+	return c.MaxEntries
+}
+
+func (c *ResponseCache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// Purge removes all cached responses.
+func (c *ResponseCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru = nil
+	c.entries = nil
+}
+
+// lookup returns the entry for key and the kind of lookup, one of "hit",
+// "stale" or "miss". On a miss, the returned entry, if any, is expired and
+// now marked as refreshing by the caller. c.mu must not be held.
+func (c *ResponseCache) lookup(key string) (cacheEntry, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, "miss"
+	}
+	c.lru.MoveToFront(el)
+	e := el.Value.(*cacheEntry)
+	now := c.timeNow()
+	if now.Before(e.expires) {
+		return *e, "hit"
+	}
+	if e.refreshing && now.Before(e.expires.Add(c.StaleWhileRefreshing)) {
+		return *e, "stale"
+	}
+	e.refreshing = true
+	return *e, "miss"
+}
+
+// store caches val for key, expiring after ttl.
+func (c *ResponseCache) store(key string, val any, etag string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.lru = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+	e := &cacheEntry{key: key, val: val, etag: etag, expires: c.timeNow().Add(ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries() {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// renew extends the expiry of the entry for key after the server confirmed
+// it is still current, and returns its value.
+func (c *ResponseCache) renew(key string, ttl time.Duration) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	e.expires = c.timeNow().Add(ttl)
+	e.refreshing = false
+	return e.val, true
+}
+
+// release clears the refreshing mark on the entry for key, if any.
+func (c *ResponseCache) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).refreshing = false
+	}
+}
+
+// remove deletes the entry for key, if any.
+func (c *ResponseCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+// HTTPCacheData carries HTTP caching information between a ResponseCache and
+// a REST transport. Before sending the request, the transport should send
+// ETag, if any, in the If-None-Match header. After receiving the response, it
+// should report the response headers with SetResponseHeader and, for a 304
+// Not Modified response, call SetNotModified and return a nil error.
+//
+// Experimental: This type is experimental and may be modified or removed in future versions,
+// regardless of any other documented package stability guarantees.
+type HTTPCacheData struct {
+	etag        string
+	header      http.Header
+	notModified bool
+}
+
+// ETag returns the entity tag of the cached response being revalidated, if
+// any.
+// Experimental: This function is experimental and may be modified or removed in future versions,
+// regardless of any other documented package stability guarantees.
+func (d *HTTPCacheData) ETag() string { return d.etag }
+
+// SetResponseHeader sets the headers of the HTTP response.
+// Experimental: This function is experimental and may be modified or removed in future versions,
+// regardless of any other documented package stability guarantees.
+func (d *HTTPCacheData) SetResponseHeader(h http.Header) { d.header = h }
+
+// SetNotModified reports that the server responded with 304 Not Modified.
+// Experimental: This function is experimental and may be modified or removed in future versions,
+// regardless of any other documented package stability guarantees.
+func (d *HTTPCacheData) SetNotModified() { d.notModified = true }
+
+// httpCacheKey is the private context key used to inject HTTPCacheData.
+type httpCacheKey struct{}
+
+// ExtractHTTPCacheData retrieves a mutable HTTPCacheData pointer from the
+// context. It returns nil if the call is not cached.
+// Experimental: This function is experimental and may be modified or removed in future versions,
+// regardless of any other documented package stability guarantees.
+func ExtractHTTPCacheData(ctx context.Context) *HTTPCacheData {
+	data, _ := ctx.Value(httpCacheKey{}).(*HTTPCacheData)
+	return data
+}
+
+// cacheControl holds the Cache-Control directives relevant to ResponseCache.
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	maxAge  time.Duration
+	// hasMaxAge reports whether maxAge was set.
+	hasMaxAge bool
+}
+
+// parseCacheControl parses the Cache-Control header in h.
+func parseCacheControl(h http.Header) cacheControl {
+	var cc cacheControl
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			d = strings.ToLower(strings.TrimSpace(d))
+			switch {
+			case d == "no-store":
+				cc.noStore = true
+			case d == "no-cache":
+				cc.noCache = true
+			case strings.HasPrefix(d, "max-age="):
+				if secs, err := strconv.Atoi(strings.TrimPrefix(d, "max-age=")); err == nil && secs >= 0 {
+					cc.maxAge = time.Duration(secs) * time.Second
+					cc.hasMaxAge = true
+				}
+			}
+		}
+	}
+	return cc
+}
+
+type responseCacheOpt struct {
+	c   *ResponseCache
+	key string
+	// get returns the response captured by this caller's APICall, and set
+	// stores a cached response for this caller.
+	get func() any
+	set func(any)
+}
+
+func (o *responseCacheOpt) Resolve(s *CallSettings) {
+	s.cache = o
+}
+
+// WithResponseCache configures Invoke to serve the response of method for req
+// from c when possible, and to populate c otherwise. It should only be used
+// for idempotent reads.
+//
+// resp must point to the variable that the APICall stores its response in.
+// Responses are cloned when they are cached and when they are served into
+// resp, so callers may modify them freely. If req cannot be serialized, the
+// call bypasses the cache.
+func WithResponseCache[T proto.Message](c *ResponseCache, method string, req proto.Message, resp *T) CallOption {
+	o := &responseCacheOpt{
+		c:   c,
+		get: func() any { return proto.Clone(*resp) },
+		set: func(v any) {
+			if t, ok := proto.Clone(v.(proto.Message)).(T); ok {
+				*resp = t
+			}
+		},
+	}
+	if b, err := (proto.MarshalOptions{Deterministic: true}).Marshal(req); err == nil {
+		o.key = method + "\x00" + string(b)
+	}
+	return o
+}
+
+// invokeCached runs invoke through the ResponseCache configured in settings.
+func invokeCached(ctx context.Context, call APICall, settings CallSettings, sp sleeper) error {
+	o := settings.cache
+	settings.cache = nil
+	if o.key == "" {
+		return invoke(ctx, call, settings, sp)
+	}
+
+	e, result := o.c.lookup(o.key)
+	if IsFeatureEnabled("METRICS") {
+		recordCacheLookup(ctx, settings, result)
+	}
+	if result != "miss" {
+		o.set(e.val)
+		return nil
+	}
+
+	data := &HTTPCacheData{etag: e.etag}
+	if err := invoke(context.WithValue(ctx, httpCacheKey{}, data), call, settings, sp); err != nil {
+		o.c.release(o.key)
+		return err
+	}
+
+	cc := parseCacheControl(data.header)
+	ttl := o.c.ttl()
+	if cc.hasMaxAge {
+		ttl = cc.maxAge
+	}
+	if cc.noCache {
+		ttl = 0
+	}
+	if data.notModified {
+		if v, ok := o.c.renew(o.key, ttl); ok {
+			o.set(v)
+			return nil
+		}
+		// The entry was evicted while it was being revalidated, so fetch
+		// the response unconditionally.
+		return invoke(ctx, call, settings, sp)
+	}
+	if cc.noStore {
+		o.c.remove(o.key)
+		return nil
+	}
+	o.c.store(o.key, o.get(), data.header.Get("ETag"), ttl)
+	return nil
+}
@@ -0,0 +1,290 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// cachedCall returns an APICall that counts its invocations and stores
+// "v<count>" in resp, along with the headers returned by header.
+func cachedCall(calls *int, resp **wrapperspb.StringValue, header func(*HTTPCacheData) http.Header) APICall {
+	return func(ctx context.Context, _ CallSettings) error {
+		*calls++
+		if data := ExtractHTTPCacheData(ctx); data != nil && header != nil {
+			data.SetResponseHeader(header(data))
+			if data.notModified {
+				return nil
+			}
+		}
+		*resp = wrapperspb.String("v" + strconv.Itoa(*calls))
+		return nil
+	}
+}
+
+func invokeCachedString(t *testing.T, c *ResponseCache, req string, call func(**wrapperspb.StringValue) APICall) string {
+	t.Helper()
+	var resp *wrapperspb.StringValue
+	var settings CallSettings
+	WithResponseCache(c, "Get", wrapperspb.String(req), &resp).Resolve(&settings)
+	var sp recordSleeper
+	if err := invoke(context.Background(), call(&resp), settings, sp.sleep); err != nil {
+		t.Fatalf("found error %v, want nil", err)
+	}
+	return resp.GetValue()
+}
+
+func TestResponseCacheTTL(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	c := &ResponseCache{TTL: time.Minute, now: clock.now}
+	calls := 0
+	call := func(resp **wrapperspb.StringValue) APICall { return cachedCall(&calls, resp, nil) }
+
+	if got := invokeCachedString(t, c, "a", call); got != "v1" {
+		t.Errorf("got %q, want v1", got)
+	}
+	if got := invokeCachedString(t, c, "a", call); got != "v1" {
+		t.Errorf("got %q, want cached v1", got)
+	}
+	if got := invokeCachedString(t, c, "b", call); got != "v2" {
+		t.Errorf("got %q for a different request, want v2", got)
+	}
+	clock.t = clock.t.Add(2 * time.Minute)
+	if got := invokeCachedString(t, c, "a", call); got != "v3" {
+		t.Errorf("got %q after expiry, want v3", got)
+	}
+	if calls != 3 {
+		t.Errorf("APICall ran %d times, want 3", calls)
+	}
+}
+
+func TestResponseCacheLRU(t *testing.T) {
+	c := &ResponseCache{MaxEntries: 2}
+	calls := 0
+	call := func(resp **wrapperspb.StringValue) APICall { return cachedCall(&calls, resp, nil) }
+
+	invokeCachedString(t, c, "a", call)
+	invokeCachedString(t, c, "b", call)
+	// Touch "a" so that "b" is the least recently used.
+	invokeCachedString(t, c, "a", call)
+	invokeCachedString(t, c, "c", call)
+	if calls != 3 {
+		t.Fatalf("APICall ran %d times, want 3", calls)
+	}
+	invokeCachedString(t, c, "a", call)
+	if calls != 3 {
+		t.Errorf("recently used entry was evicted")
+	}
+	invokeCachedString(t, c, "b", call)
+	if calls != 4 {
+		t.Errorf("least recently used entry was not evicted")
+	}
+}
+
+func TestResponseCacheStaleWhileRefreshing(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	c := &ResponseCache{TTL: time.Minute, StaleWhileRefreshing: time.Minute, now: clock.now}
+	calls := 0
+	call := func(resp **wrapperspb.StringValue) APICall { return cachedCall(&calls, resp, nil) }
+	invokeCachedString(t, c, "a", call)
+	clock.t = clock.t.Add(90 * time.Second)
+
+	// The first caller after expiry revalidates. Callers arriving meanwhile
+	// are served the stale response.
+	var stale string
+	revalidating := func(resp **wrapperspb.StringValue) APICall {
+		return func(ctx context.Context, settings CallSettings) error {
+			stale = invokeCachedString(t, c, "a", call)
+			return cachedCall(&calls, resp, nil)(ctx, settings)
+		}
+	}
+	if got := invokeCachedString(t, c, "a", revalidating); got != "v2" {
+		t.Errorf("got %q from revalidation, want v2", got)
+	}
+	if stale != "v1" {
+		t.Errorf("got %q during revalidation, want stale v1", stale)
+	}
+	if got := invokeCachedString(t, c, "a", call); got != "v2" {
+		t.Errorf("got %q after revalidation, want v2", got)
+	}
+}
+
+func TestResponseCacheControl(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	for _, tst := range []struct {
+		name      string
+		header    string
+		advance   time.Duration
+		wantCalls int
+	}{
+		{"no_store", "no-store", 0, 2},
+		{"no_cache", "no-cache", 0, 2},
+		{"max_age_fresh", "public, max-age=600", 5 * time.Minute, 1},
+		{"max_age_expired", "max-age=10", time.Minute, 2},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			c := &ResponseCache{TTL: time.Minute, now: clock.now}
+			calls := 0
+			call := func(resp **wrapperspb.StringValue) APICall {
+				return cachedCall(&calls, resp, func(*HTTPCacheData) http.Header {
+					return http.Header{"Cache-Control": []string{tst.header}}
+				})
+			}
+			invokeCachedString(t, c, "a", call)
+			clock.t = clock.t.Add(tst.advance)
+			invokeCachedString(t, c, "a", call)
+			if calls != tst.wantCalls {
+				t.Errorf("APICall ran %d times, want %d", calls, tst.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResponseCacheETag(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	c := &ResponseCache{TTL: time.Minute, now: clock.now}
+	calls := 0
+	var gotETags []string
+	call := func(resp **wrapperspb.StringValue) APICall {
+		return cachedCall(&calls, resp, func(data *HTTPCacheData) http.Header {
+			gotETags = append(gotETags, data.ETag())
+			if data.ETag() == `"abc"` {
+				data.SetNotModified()
+			}
+			return http.Header{"Etag": []string{`"abc"`}}
+		})
+	}
+	invokeCachedString(t, c, "a", call)
+	clock.t = clock.t.Add(2 * time.Minute)
+	if got := invokeCachedString(t, c, "a", call); got != "v1" {
+		t.Errorf("got %q after 304, want v1", got)
+	}
+	if got := invokeCachedString(t, c, "a", call); got != "v1" {
+		t.Errorf("got %q after renewal, want cached v1", got)
+	}
+	if want := []string{"", `"abc"`}; len(gotETags) != 2 || gotETags[0] != want[0] || gotETags[1] != want[1] {
+		t.Errorf("got ETags %q, want %q", gotETags, want)
+	}
+}
+
+func TestResponseCacheError(t *testing.T) {
+	c := &ResponseCache{}
+	apiErr := errors.New("foo error")
+	var resp *wrapperspb.StringValue
+	var settings CallSettings
+	WithResponseCache(c, "Get", wrapperspb.String("a"), &resp).Resolve(&settings)
+	var sp recordSleeper
+	err := invoke(context.Background(), func(context.Context, CallSettings) error { return apiErr }, settings, sp.sleep)
+	if err != apiErr {
+		t.Errorf("found error %v, want %v", err, apiErr)
+	}
+	if len(c.entries) != 0 {
+		t.Errorf("got %d cached entries after error, want 0", len(c.entries))
+	}
+}
+
+func TestResponseCacheMetrics(t *testing.T) {
+	t.Setenv("GOOGLE_SDK_GO_EXPERIMENTAL_METRICS", "true")
+	TestOnlyResetIsFeatureEnabled()
+	defer TestOnlyResetIsFeatureEnabled()
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	cm := NewClientMetrics(WithMeterProvider(provider))
+
+	c := &ResponseCache{}
+	calls := 0
+	for i := 0; i < 3; i++ {
+		var resp *wrapperspb.StringValue
+		var settings CallSettings
+		WithClientMetrics(cm).Resolve(&settings)
+		WithResponseCache(c, "Get", wrapperspb.String("a"), &resp).Resolve(&settings)
+		var sp recordSleeper
+		invoke(context.Background(), cachedCall(&calls, &resp, nil), settings, sp.sleep)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != cacheMetricName {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				v, _ := dp.Attributes.Value("gcp.client.response_cache.result")
+				got[v.AsString()] += dp.Value
+			}
+		}
+	}
+	if got["hit"] != 2 || got["miss"] != 1 {
+		t.Errorf("got lookups %v, want 2 hits and 1 miss", got)
+	}
+}
+
+func TestResponseCacheIsolation(t *testing.T) {
+	c := &ResponseCache{}
+	calls := 0
+	get := func() *wrapperspb.StringValue {
+		var resp *wrapperspb.StringValue
+		var settings CallSettings
+		WithResponseCache(c, "Get", wrapperspb.String("a"), &resp).Resolve(&settings)
+		var sp recordSleeper
+		if err := invoke(context.Background(), cachedCall(&calls, &resp, nil), settings, sp.sleep); err != nil {
+			t.Fatalf("found error %v, want nil", err)
+		}
+		return resp
+	}
+
+	first := get()
+	first.Value = "modified by caller"
+	second := get()
+	if second.GetValue() != "v1" {
+		t.Errorf("got %q after the first caller modified its response, want v1", second.GetValue())
+	}
+	second.Value = "modified again"
+	if got := get().GetValue(); got != "v1" {
+		t.Errorf("got %q after a cached response was modified, want v1", got)
+	}
+	if calls != 1 {
+		t.Errorf("APICall ran %d times, want 1", calls)
+	}
+}
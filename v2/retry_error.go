@@ -0,0 +1,81 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import "time"
+
+// RetryAttempt describes a single failed APICall attempt made by Invoke.
+type RetryAttempt struct {
+	// Err is the error returned by the attempt.
+	Err error
+	// Start is the time at which the attempt started.
+	Start time.Time
+	// Duration is how long the attempt took.
+	Duration time.Duration
+	// Pause is the time Invoke waited after the attempt before retrying. It
+	// is zero if the attempt was not retried.
+	Pause time.Duration
+	// ResendCount is the number of attempts made before this one. It is zero
+	// for the initial attempt, matching the resend_count telemetry attribute.
+	ResendCount int
+}
+
+// RetryError is returned by Invoke when the WithRetryError option is set and
+// the call fails. It wraps the final error, so errors.Is, errors.As and
+// apierror.FromError behave as they would on the final error, and records
+// every failed attempt leading up to it.
+type RetryError struct {
+	// Attempts holds every failed attempt, in the order they were made.
+	Attempts []RetryAttempt
+
+	err error
+}
+
+// Error returns the message of the final error.
+func (e *RetryError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the final error.
+func (e *RetryError) Unwrap() error {
+	return e.err
+}
+
+type retryErrorOpt struct{}
+
+func (retryErrorOpt) Resolve(s *CallSettings) {
+	s.retryError = true
+}
+
+// WithRetryError configures Invoke to return a *RetryError that records the
+// history of all attempts when the call fails.
+func WithRetryError() CallOption {
+	return retryErrorOpt{}
+}
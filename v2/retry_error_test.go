@@ -0,0 +1,134 @@
+// Copyright 2026, Google Inc.
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//     * Redistributions of source code must retain the above copyright
+// notice, this list of conditions and the following disclaimer.
+//     * Redistributions in binary form must reproduce the above
+// copyright notice, this list of conditions and the following disclaimer
+// in the documentation and/or other materials provided with the
+// distribution.
+//     * Neither the name of Google Inc. nor the names of its
+// contributors may be used to endorse or promote products derived from
+// this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT
+// OWNER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL,
+// SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT
+// LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+// DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+// THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+// (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+// OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+package gax
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/googleapis/gax-go/v2/apierror"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type constRetryer struct {
+	pause time.Duration
+	max   int
+	n     int
+}
+
+func (r *constRetryer) Retry(error) (time.Duration, bool) {
+	if r.n >= r.max {
+		return 0, false
+	}
+	r.n++
+	return r.pause, true
+}
+
+func TestInvokeRetryError(t *testing.T) {
+	errs := []error{
+		status.Error(codes.Unavailable, "first"),
+		status.Error(codes.Unavailable, "second"),
+		status.Error(codes.Internal, "third"),
+	}
+	calls := 0
+	apiCall := func(context.Context, CallSettings) error {
+		err := errs[calls]
+		calls++
+		return err
+	}
+	var settings CallSettings
+	WithRetry(func() Retryer { return &constRetryer{pause: time.Millisecond, max: 2} }).Resolve(&settings)
+	WithRetryError().Resolve(&settings)
+	var sp recordSleeper
+	err := invoke(context.Background(), apiCall, settings, sp.sleep)
+
+	var rerr *RetryError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("got error %T, want *RetryError", err)
+	}
+	if got, want := err.Error(), errs[2].Error(); got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	if apierr, ok := apierror.FromError(err); !ok || apierr.GRPCStatus().Code() != codes.Internal {
+		t.Errorf("apierror.FromError(%v) = %v, %t; want code %v", err, apierr, ok, codes.Internal)
+	}
+	if len(rerr.Attempts) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(rerr.Attempts))
+	}
+	for i, a := range rerr.Attempts {
+		if a.Err != errs[i] {
+			t.Errorf("attempt %d: got error %v, want %v", i, a.Err, errs[i])
+		}
+		if a.ResendCount != i {
+			t.Errorf("attempt %d: got resend count %d, want %d", i, a.ResendCount, i)
+		}
+		if a.Start.IsZero() {
+			t.Errorf("attempt %d: start time not recorded", i)
+		}
+		wantPause := time.Millisecond
+		if i == len(rerr.Attempts)-1 {
+			wantPause = 0
+		}
+		if a.Pause != wantPause {
+			t.Errorf("attempt %d: got pause %v, want %v", i, a.Pause, wantPause)
+		}
+	}
+}
+
+func TestInvokeRetryErrorSuccess(t *testing.T) {
+	calls := 0
+	apiCall := func(context.Context, CallSettings) error {
+		calls++
+		if calls == 1 {
+			return errors.New("foo error")
+		}
+		return nil
+	}
+	var settings CallSettings
+	WithRetry(func() Retryer { return boolRetryer(true) }).Resolve(&settings)
+	WithRetryError().Resolve(&settings)
+	var sp recordSleeper
+	if err := invoke(context.Background(), apiCall, settings, sp.sleep); err != nil {
+		t.Errorf("found error %v, want nil", err)
+	}
+}
+
+func TestInvokeRetryErrorDisabled(t *testing.T) {
+	apiErr := errors.New("foo error")
+	apiCall := func(context.Context, CallSettings) error { return apiErr }
+	var sp recordSleeper
+	err := invoke(context.Background(), apiCall, CallSettings{}, sp.sleep)
+	if err != apiErr {
+		t.Errorf("found error %v, want %v", err, apiErr)
+	}
+}
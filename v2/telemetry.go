@@ -112,6 +112,24 @@ const (
 	metricName        = "gcp.client.request.duration"
 	metricDescription = "Duration of the request to the Google Cloud API"
 
+	streamMetricName        = "gcp.client.stream.establishment.duration"
+	streamMetricDescription = "Duration of establishing a stream to the Google Cloud API"
+
+	cacheMetricName        = "gcp.client.response_cache.lookups"
+	cacheMetricDescription = "Number of response cache lookups for the Google Cloud API"
+
+	flowElementsMetricName        = "gcp.client.flow_control.outstanding_elements"
+	flowElementsMetricDescription = "Number of elements held by flow control for the Google Cloud API"
+	flowBytesMetricName           = "gcp.client.flow_control.outstanding_bytes"
+	flowBytesMetricDescription    = "Number of bytes held by flow control for the Google Cloud API"
+
+	pagesMetricName            = "gcp.client.pagination.pages"
+	pagesMetricDescription     = "Number of pages fetched from the Google Cloud API"
+	pageItemsMetricName        = "gcp.client.pagination.page.items"
+	pageItemsMetricDescription = "Number of items in the pages fetched from the Google Cloud API"
+	pageMetricName             = "gcp.client.pagination.page.duration"
+	pageMetricDescription      = "Duration of fetching a page from the Google Cloud API, including retries"
+
 	// Constants for ClientMetrics configuration map keys.
 	// These are used by generated clients to pass attributes to the ClientMetrics option.
 	// Because they are used in generated code, these values must not be changed.
@@ -150,8 +168,15 @@ type ClientMetrics struct {
 }
 
 type clientMetricsData struct {
-	duration metric.Float64Histogram
-	attr     []attribute.KeyValue
+	duration     metric.Float64Histogram
+	establish    metric.Float64Histogram
+	cacheLookups metric.Int64Counter
+	flowElements metric.Int64UpDownCounter
+	flowBytes    metric.Int64UpDownCounter
+	pages        metric.Int64Counter
+	pageItems    metric.Int64Histogram
+	pageDuration metric.Float64Histogram
+	attr         []attribute.KeyValue
 }
 
 type telemetryOptions struct {
@@ -273,6 +298,71 @@ func NewClientMetrics(opts ...TelemetryOption) *ClientMetrics {
 				config.logger.Warn("failed to initialize OTel duration histogram", "error", err)
 			}
 
+			establish, err := meter.Float64Histogram(
+				streamMetricName,
+				metric.WithDescription(streamMetricDescription),
+				metric.WithUnit("s"),
+				metric.WithExplicitBucketBoundaries(boundaries...),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel stream establishment histogram", "error", err)
+			}
+
+			cacheLookups, err := meter.Int64Counter(
+				cacheMetricName,
+				metric.WithDescription(cacheMetricDescription),
+				metric.WithUnit("{lookup}"),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel cache lookup counter", "error", err)
+			}
+
+			flowElements, err := meter.Int64UpDownCounter(
+				flowElementsMetricName,
+				metric.WithDescription(flowElementsMetricDescription),
+				metric.WithUnit("{element}"),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel flow control elements counter", "error", err)
+			}
+
+			flowBytes, err := meter.Int64UpDownCounter(
+				flowBytesMetricName,
+				metric.WithDescription(flowBytesMetricDescription),
+				metric.WithUnit("By"),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel flow control bytes counter", "error", err)
+			}
+
+			pages, err := meter.Int64Counter(
+				pagesMetricName,
+				metric.WithDescription(pagesMetricDescription),
+				metric.WithUnit("{page}"),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel page counter", "error", err)
+			}
+
+			pageItems, err := meter.Int64Histogram(
+				pageItemsMetricName,
+				metric.WithDescription(pageItemsMetricDescription),
+				metric.WithUnit("{item}"),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel page items histogram", "error", err)
+			}
+
+			pageDuration, err := meter.Float64Histogram(
+				pageMetricName,
+				metric.WithDescription(pageMetricDescription),
+				metric.WithUnit("s"),
+				metric.WithExplicitBucketBoundaries(boundaries...),
+			)
+			if err != nil && config.logger != nil {
+				config.logger.Warn("failed to initialize OTel page duration histogram", "error", err)
+			}
+
 			var attr []attribute.KeyValue
 			if val, ok := config.attributes[URLDomain]; ok {
 				attr = append(attr, attribute.KeyValue{Key: attribute.Key(keyURLDomain), Value: attribute.StringValue(val)})
@@ -281,8 +371,15 @@ func NewClientMetrics(opts ...TelemetryOption) *ClientMetrics {
 				attr = append(attr, attribute.KeyValue{Key: attribute.Key(keyRPCSystemName), Value: attribute.StringValue(val)})
 			}
 			return clientMetricsData{
-				duration: duration,
-				attr:     attr,
+				duration:     duration,
+				establish:    establish,
+				cacheLookups: cacheLookups,
+				flowElements: flowElements,
+				flowBytes:    flowBytes,
+				pages:        pages,
+				pageItems:    pageItems,
+				pageDuration: pageDuration,
+				attr:         attr,
 			}
 		}),
 	}
@@ -295,6 +392,36 @@ func (cm *ClientMetrics) durationHistogram() metric.Float64Histogram {
 	return cm.get().duration
 }
 
+func (cm *ClientMetrics) establishmentHistogram() metric.Float64Histogram {
+	if cm == nil || cm.get == nil {
+		return nil
+	}
+	return cm.get().establish
+}
+
+func (cm *ClientMetrics) cacheLookupCounter() metric.Int64Counter {
+	if cm == nil || cm.get == nil {
+		return nil
+	}
+	return cm.get().cacheLookups
+}
+
+func (cm *ClientMetrics) flowControlCounters() (elements, bytes metric.Int64UpDownCounter) {
+	if cm == nil || cm.get == nil {
+		return nil, nil
+	}
+	d := cm.get()
+	return d.flowElements, d.flowBytes
+}
+
+func (cm *ClientMetrics) pageInstruments() (pages metric.Int64Counter, items metric.Int64Histogram, duration metric.Float64Histogram) {
+	if cm == nil || cm.get == nil {
+		return nil, nil, nil
+	}
+	d := cm.get()
+	return d.pages, d.pageItems, d.pageDuration
+}
+
 func (cm *ClientMetrics) attributes() []attribute.KeyValue {
 	if cm == nil || cm.get == nil {
 		return nil
@@ -438,9 +565,15 @@ func ExtractTelemetryErrorInfo(ctx context.Context, err error) TelemetryErrorInf
 	}
 }
 
-// recordMetric records a duration measurement for the configured metric.
+// recordMetric records a duration measurement for the configured metric. For
+// calls made by InvokeStream, the stream establishment metric is used instead
+// of the request duration metric.
 func recordMetric(ctx context.Context, settings CallSettings, d time.Duration, err error) {
-	if settings.clientMetrics == nil || settings.clientMetrics.durationHistogram() == nil {
+	histogram := settings.clientMetrics.durationHistogram()
+	if settings.stream {
+		histogram = settings.clientMetrics.establishmentHistogram()
+	}
+	if histogram == nil {
 		return
 	}
 
@@ -479,5 +612,54 @@ func recordMetric(ctx context.Context, settings CallSettings, d time.Duration, e
 		attrs = append(attrs, attribute.String("url.template", urlTemplate))
 	}
 
-	settings.clientMetrics.durationHistogram().Record(recordCtx, d.Seconds(), metric.WithAttributes(attrs...))
+	histogram.Record(recordCtx, d.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// recordCacheLookup records a response cache lookup with the given result,
+// one of "hit", "stale" or "miss".
+func recordCacheLookup(ctx context.Context, settings CallSettings, result string) {
+	if settings.clientMetrics == nil || settings.clientMetrics.cacheLookupCounter() == nil {
+		return
+	}
+	attrs := make([]attribute.KeyValue, 0, len(settings.clientMetrics.attributes())+2)
+	attrs = append(attrs, settings.clientMetrics.attributes()...)
+	attrs = append(attrs, attribute.String("gcp.client.response_cache.result", result))
+	if rpcMethod, ok := callctx.TelemetryFromContext(ctx, "rpc_method"); ok && rpcMethod != "" {
+		attrs = append(attrs, attribute.String("rpc.method", rpcMethod))
+	}
+	settings.clientMetrics.cacheLookupCounter().Add(context.WithoutCancel(ctx), 1, metric.WithAttributes(attrs...))
+}
+
+// RecordPageMetrics records the fetch of a page of items for a paginated
+// method into the ClientMetrics set in opts with WithClientMetrics, if any. d
+// is the time taken to fetch the page including any retries, and err the
+// final error of the fetch.
+//
+// Experimental: This function is experimental and may be modified or removed in future versions,
+// regardless of any other documented package stability guarantees.
+func RecordPageMetrics(ctx context.Context, items int, d time.Duration, err error, opts ...CallOption) {
+	var settings CallSettings
+	for _, opt := range opts {
+		opt.Resolve(&settings)
+	}
+	cm := settings.clientMetrics
+	pages, itemsHistogram, duration := cm.pageInstruments()
+	if pages == nil || itemsHistogram == nil || duration == nil {
+		return
+	}
+	recordCtx := context.WithoutCancel(ctx)
+
+	attrs := make([]attribute.KeyValue, 0, len(cm.attributes())+2)
+	attrs = append(attrs, cm.attributes()...)
+	attrs = append(attrs, attribute.String("rpc.response.status_code", ExtractTelemetryErrorInfo(ctx, err).StatusCode))
+	if rpcMethod, ok := callctx.TelemetryFromContext(ctx, "rpc_method"); ok && rpcMethod != "" {
+		attrs = append(attrs, attribute.String("rpc.method", rpcMethod))
+	}
+	set := metric.WithAttributes(attrs...)
+
+	pages.Add(recordCtx, 1, set)
+	if err == nil {
+		itemsHistogram.Record(recordCtx, int64(items), set)
+	}
+	duration.Record(recordCtx, d.Seconds(), set)
 }
@@ -633,6 +633,12 @@ func TestClientMetrics_NilReceiver(t *testing.T) {
 	if cm.durationHistogram() != nil {
 		t.Errorf("expected nil durationHistogram for nil receiver")
 	}
+	if cm.cacheLookupCounter() != nil {
+		t.Errorf("expected nil cacheLookupCounter for nil receiver")
+	}
+	if pages, items, duration := cm.pageInstruments(); pages != nil || items != nil || duration != nil {
+		t.Errorf("expected nil page instruments for nil receiver")
+	}
 	if cm.attributes() != nil {
 		t.Errorf("expected nil attributes for nil receiver")
 	}
@@ -645,3 +651,56 @@ func TestClientMetrics_NilReceiver(t *testing.T) {
 		t.Errorf("expected nil attributes for uninitialized ClientMetrics")
 	}
 }
+
+func TestRecordPageMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	cm := NewClientMetrics(WithMeterProvider(provider))
+
+	ctx := callctx.WithTelemetryContext(context.Background(), "rpc_method", "my.service.List")
+	RecordPageMetrics(ctx, 5, time.Second, nil, WithClientMetrics(cm))
+	RecordPageMetrics(ctx, 0, 2*time.Second, status.Error(codes.Unavailable, "unavailable"), WithClientMetrics(cm))
+	// Without ClientMetrics, nothing is recorded.
+	RecordPageMetrics(ctx, 3, time.Second, nil)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+	got := map[string]any{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				var sum int64
+				for _, dp := range data.DataPoints {
+					if v, _ := dp.Attributes.Value("rpc.method"); v.AsString() != "my.service.List" {
+						t.Errorf("%s: rpc.method = %q, want %q", m.Name, v.AsString(), "my.service.List")
+					}
+					sum += dp.Value
+				}
+				got[m.Name] = sum
+			case metricdata.Histogram[int64]:
+				var sum int64
+				for _, dp := range data.DataPoints {
+					sum += dp.Sum
+				}
+				got[m.Name] = sum
+			case metricdata.Histogram[float64]:
+				var sum float64
+				for _, dp := range data.DataPoints {
+					sum += dp.Sum
+				}
+				got[m.Name] = sum
+			}
+		}
+	}
+	want := map[string]any{
+		pagesMetricName:     int64(2),
+		pageItemsMetricName: int64(5),
+		pageMetricName:      3.0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("recorded metrics mismatch (-want +got):\n%s", diff)
+	}
+}